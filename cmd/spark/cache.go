@@ -0,0 +1,64 @@
+package main
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+func (c *cli) cacheCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the response cache",
+		Args:  cobra.NoArgs,
+	}
+
+	cmd.AddCommand(c.cacheStatsCmd())
+	cmd.AddCommand(c.cachePurgeCmd())
+
+	return cmd
+}
+
+func (c *cli) cacheStatsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Show cache statistics",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			stats, err := c.app.CacheStats()
+			if err != nil {
+				return err
+			}
+
+			stats.PrintTo(os.Stdout)
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func (c *cli) cachePurgeCmd() *cobra.Command {
+	var expiredOnly bool
+
+	cmd := &cobra.Command{
+		Use:   "purge",
+		Short: "Delete cached responses and their statistics",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			n, err := c.app.PurgeCache(expiredOnly)
+			if err != nil {
+				return err
+			}
+
+			c.app.Logger().Info("Cache purged", "responses", n)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&expiredOnly, "expired", false, "Only delete expired responses")
+
+	return cmd
+}
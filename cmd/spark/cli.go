@@ -31,6 +31,7 @@ func (c *cli) root() *cobra.Command {
 	}
 
 	cmd.AddCommand(c.entriesCmd())
+	cmd.AddCommand(c.tasksCmd())
 	cmd.AddCommand(c.sourcesCmd())
 	cmd.AddCommand(c.mailerCmd())
 	cmd.AddCommand(c.printCmd())
@@ -41,6 +42,9 @@ func (c *cli) root() *cobra.Command {
 	cmd.AddCommand(c.icalCmd())
 	cmd.AddCommand(c.vcfCmd())
 	cmd.AddCommand(c.rssCmd())
+	cmd.AddCommand(c.cacheCmd())
+	cmd.AddCommand(c.usageCmd())
+	cmd.AddCommand(c.personasCmd())
 
 	sparkConfig, ok := os.LookupEnv("SPARK_CONFIG")
 	if !ok {
@@ -48,6 +52,7 @@ func (c *cli) root() *cobra.Command {
 	}
 
 	cmd.PersistentFlags().StringVar(&c.app.ConfigFile, "config", sparkConfig, "config file")
+	cmd.PersistentFlags().StringVar(&c.app.AsOf, "as-of", "", "run as if today is this day (YYYY-MM-DD)")
 
 	return cmd
 }
@@ -1,8 +1,12 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/jovandeginste/spark-personal-assistant/pkg/app"
 	"github.com/jovandeginste/spark-personal-assistant/pkg/data"
@@ -17,9 +21,40 @@ func (c *cli) entriesCmd() *cobra.Command {
 	}
 
 	cmd.AddCommand(c.listEntriesCmd())
+	cmd.AddCommand(c.searchEntriesCmd())
 	cmd.AddCommand(c.addEntryCmd())
 	cmd.AddCommand(c.showEntryCmd())
+	cmd.AddCommand(c.updateEntryCmd())
 	cmd.AddCommand(c.deleteEntryCmd())
+	cmd.AddCommand(c.indexEntriesCmd())
+	cmd.AddCommand(c.seriesEntriesCmd())
+	cmd.AddCommand(c.stopEntryCmd())
+	cmd.AddCommand(c.skipEntryCmd())
+
+	return cmd
+}
+
+func (c *cli) indexEntriesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "index",
+		Short: "Compute the embeddings of new and changed entries, for --retrieve",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			aiClient, err := c.app.AIClient()
+			if err != nil {
+				return err
+			}
+
+			n, err := c.app.IndexEntries(c.app.TrackUsage(context.Background(), "index", ""), aiClient)
+			if err != nil {
+				return err
+			}
+
+			c.app.Logger().Info("Entries indexed", "entries", n, "model", aiClient.EmbeddingModel())
+
+			return nil
+		},
+	}
 
 	return cmd
 }
@@ -81,12 +116,118 @@ func (c *cli) showEntryCmd() *cobra.Command {
 	return cmd
 }
 
+func (c *cli) updateEntryCmd() *cobra.Command {
+	var (
+		title     string
+		d         string
+		i         string
+		s         string
+		setMeta   []string
+		unsetMeta []string
+		tags      []string
+		untags    []string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "update id",
+		Short: "Change an entry; the next import of its source keeps the changes",
+		Long: `Change an entry; only the given fields change.
+
+The ID and remote ID of the entry stay the same, and the next import of its
+source keeps the changes, unless the source no longer has the entry. An entry that is moved to another source is no
+longer replaced by the import of its original source, and the imports of its
+new source keep it.`,
+		Example: `spark entries update 12 --title "Dentist appointment" -i high
+spark entries update 12 --set location="Main street 1" --unset url`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := strconv.ParseUint(args[0], 10, 64)
+			if err != nil {
+				return err
+			}
+
+			e := &data.Entry{ID: id}
+
+			if err := c.app.FindEntry(e); err != nil {
+				return err
+			}
+
+			flags := cmd.Flags()
+
+			if flags.Changed("title") {
+				e.Summary = title
+			}
+
+			if flags.Changed("date") {
+				if err := e.MoveTo(d, c.app.Now()); err != nil {
+					return err
+				}
+			}
+
+			if flags.Changed("importance") {
+				if err := e.SetImportance(i); err != nil {
+					return err
+				}
+			}
+
+			if flags.Changed("source") {
+				src, err := c.app.FindSourceByName(s)
+				if err != nil {
+					return err
+				}
+
+				e.Source = src
+				e.SourceID = src.ID
+			}
+
+			for _, kv := range setMeta {
+				k, v, ok := strings.Cut(kv, "=")
+				if !ok || k == "" {
+					return fmt.Errorf("invalid metadata %q, expected key=value", kv)
+				}
+
+				e.SetMetadata(k, v)
+			}
+
+			for _, k := range unsetMeta {
+				e.UnsetMetadata(k)
+			}
+
+			e.AddTags(tags...)
+			e.RemoveTags(untags...)
+
+			if err := c.app.UpdateEntry(e); err != nil {
+				return err
+			}
+
+			c.app.Logger().Info("Entry updated")
+			e.PrintTo(os.Stdout)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&title, "title", "t", "", "New title of the entry")
+	cmd.Flags().StringVarP(&i, "importance", "i", "", "New importance of the entry")
+	cmd.Flags().StringVarP(&d, "date", "d", "", "New date of the entry, eg. next friday; the time of day is kept unless one is given")
+	cmd.Flags().StringVarP(&s, "source", "s", "", "New source of the entry")
+	cmd.Flags().StringArrayVar(&setMeta, "set", nil, "Set a metadata key, as key=value")
+	cmd.Flags().StringArrayVar(&unsetMeta, "unset", nil, "Remove a metadata key")
+	cmd.Flags().StringSliceVar(&tags, "tag", nil, "Add tags to the entry")
+	cmd.Flags().StringSliceVar(&untags, "untag", nil, "Remove tags from the entry")
+
+	return cmd
+}
+
 func (c *cli) addEntryCmd() *cobra.Command {
 	var (
-		e data.Entry
-		d string
-		i string
-		s string
+		e      data.Entry
+		d      string
+		i      string
+		s      string
+		repeat string
+		except []string
+		tags   []string
 	)
 
 	cmd := &cobra.Command{
@@ -101,7 +242,7 @@ func (c *cli) addEntryCmd() *cobra.Command {
 
 			e.Source = src
 
-			if err := e.SetDate(d); err != nil {
+			if err := e.SetDate(d, c.app.Now()); err != nil {
 				return err
 			}
 
@@ -109,6 +250,18 @@ func (c *cli) addEntryCmd() *cobra.Command {
 				return err
 			}
 
+			if err := e.SetRepeat(repeat); err != nil {
+				return err
+			}
+
+			for _, x := range except {
+				if err := e.AddException(x); err != nil {
+					return err
+				}
+			}
+
+			e.AddTags(tags...)
+
 			if err := c.app.CreateEntry(&e); err != nil {
 				return err
 			}
@@ -122,12 +275,127 @@ func (c *cli) addEntryCmd() *cobra.Command {
 
 	cmd.Flags().StringVarP(&e.Summary, "title", "t", "", "Title of the entry")
 	cmd.Flags().StringVarP(&i, "importance", "i", string(data.MEDIUM), "Importance of the entry")
-	cmd.Flags().StringVarP(&d, "date", "d", "", "Date of the entry")
+	cmd.Flags().StringVarP(&d, "date", "d", "", "Date of the entry, with an optional time, eg. 2025-06-03, tomorrow or next friday 15:30; next friday is the friday of next week")
 	cmd.Flags().StringVarP(&s, "source", "s", "manual", "Source of the entry")
+	cmd.Flags().StringVarP(&repeat, "repeat", "r", "", "Repeat the entry with an RRULE, eg. FREQ=WEEKLY;BYDAY=TU")
+	cmd.Flags().StringSliceVar(&except, "except", nil, "Dates without an occurrence of a repeating entry")
+	cmd.Flags().StringSliceVar(&tags, "tag", nil, "Tags of the entry, eg. kids,school")
+
+	return cmd
+}
+
+func (c *cli) seriesEntriesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "series",
+		Short: "List the repeating entries",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			entries, err := c.app.RecurringEntries()
+			if err != nil {
+				return err
+			}
+
+			entries.PrintSeriesTo(os.Stdout, c.app.Now())
+
+			return nil
+		},
+	}
 
 	return cmd
 }
 
+func (c *cli) stopEntryCmd() *cobra.Command {
+	var from string
+
+	cmd := &cobra.Command{
+		Use:     "stop id",
+		Short:   "Stop a repeating entry",
+		Example: "spark entries stop 12 --from 2025-09-01",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			e, err := c.findSeries(args[0])
+			if err != nil {
+				return err
+			}
+
+			t := c.app.Today()
+
+			if from != "" {
+				if t, err = time.ParseInLocation("2006-01-02", from, data.LocalTimezone); err != nil {
+					return err
+				}
+			}
+
+			if err := e.StopRepeat(t); err != nil {
+				return err
+			}
+
+			if err := c.app.UpdateEntry(e); err != nil {
+				return err
+			}
+
+			c.app.Logger().Info("Series stopped")
+			e.PrintTo(os.Stdout)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&from, "from", "", "First date without occurrences; defaults to today")
+
+	return cmd
+}
+
+func (c *cli) skipEntryCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "skip id date",
+		Short:   "Skip one occurrence of a repeating entry",
+		Example: "spark entries skip 12 2025-12-25",
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			e, err := c.findSeries(args[0])
+			if err != nil {
+				return err
+			}
+
+			if err := e.AddException(args[1]); err != nil {
+				return err
+			}
+
+			if err := c.app.UpdateEntry(e); err != nil {
+				return err
+			}
+
+			c.app.Logger().Info("Occurrence skipped")
+			e.PrintTo(os.Stdout)
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// findSeries returns the repeating entry with the id
+func (c *cli) findSeries(arg string) (*data.Entry, error) {
+	id, err := strconv.ParseUint(arg, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &data.Entry{ID: id}
+
+	if err := c.app.FindEntry(e); err != nil {
+		return nil, err
+	}
+
+	if e.Repeat == "" {
+		return nil, fmt.Errorf("entry %d does not repeat", id)
+	}
+
+	return e, nil
+}
+
 func (c *cli) listEntriesCmd() *cobra.Command {
 	var (
 		ef     app.EntryFilter
@@ -161,7 +429,64 @@ func (c *cli) listEntriesCmd() *cobra.Command {
 
 	cmd.Flags().UintVarP(&ef.DaysBack, "days-back", "b", 3, "Number of days in the past to include")
 	cmd.Flags().UintVarP(&ef.DaysAhead, "days-ahead", "a", 7, "Number of days in the future to include")
+	cmd.Flags().StringSliceVar(&ef.Tags, "tag", nil, "Only include entries with any of these tags")
+	cmd.Flags().StringSliceVar(&ef.ExcludeTags, "exclude-tag", nil, "Leave out entries with any of these tags")
+	cmd.Flags().StringVarP(&source, "source", "s", "", "Source to filter for")
+
+	return cmd
+}
+
+func (c *cli) searchEntriesCmd() *cobra.Command {
+	var (
+		sf     app.SearchFilter
+		from   string
+		to     string
+		source string
+	)
+
+	cmd := &cobra.Command{
+		Use:     "search query",
+		Short:   "Search the title and details of all entries, best matches first",
+		Example: `spark entries search plumber --from 2025-01-01`,
+		Args:    cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var err error
+
+			if from != "" {
+				if sf.From, err = time.ParseInLocation("2006-01-02", from, data.LocalTimezone); err != nil {
+					return err
+				}
+			}
+
+			if to != "" {
+				if sf.To, err = time.ParseInLocation("2006-01-02", to, data.LocalTimezone); err != nil {
+					return err
+				}
+
+				sf.To = sf.To.AddDate(0, 0, 1).Add(-time.Second)
+			}
+
+			if source != "" {
+				if sf.Source, err = c.app.FindSourceByName(source); err != nil {
+					return err
+				}
+			}
+
+			entries, err := c.app.FullTextSearch(strings.Join(args, " "), sf)
+			if err != nil {
+				return err
+			}
+
+			entries.PrintTo(os.Stdout)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&from, "from", "", "First date to include, formatted as YYYY-MM-DD")
+	cmd.Flags().StringVar(&to, "to", "", "Last date to include, formatted as YYYY-MM-DD")
 	cmd.Flags().StringVarP(&source, "source", "s", "", "Source to filter for")
+	cmd.Flags().IntVarP(&sf.Limit, "limit", "l", 20, "Maximum number of results")
 
 	return cmd
 }
@@ -27,7 +27,7 @@ func (c *cli) icalCmd() *cobra.Command {
 				collection = args[2]
 			}
 
-			entries, err := ical.BuildEntriesFromRemote(args[1], daysBack, daysAhead, collection)
+			entries, err := ical.BuildEntriesFromRemote(args[1], c.app.Now(), daysBack, daysAhead, collection)
 			if err != nil {
 				return err
 			}
@@ -1,42 +1,124 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"os"
 
+	"github.com/jovandeginste/spark-personal-assistant/pkg/app"
+	"github.com/jovandeginste/spark-personal-assistant/pkg/data"
 	"github.com/jovandeginste/spark-personal-assistant/pkg/markdown"
 	"github.com/spf13/cobra"
 )
 
 func (c *cli) mailerCmd() *cobra.Command {
-	var subject string
+	var (
+		subject string
+		format  string
+		ef      app.EntryFilter
+	)
 
 	cmd := &cobra.Command{
-		Use:     "mailer input.md recipient1 recipient2 ...",
-		Short:   "Send mails",
-		Example: "spark mailer ./md/summary-full.md me@example.com",
-		Args:    cobra.MinimumNArgs(2),
+		Use:   "mailer [input.md] recipient1 recipient2 ...",
+		Short: "Send mails",
+		Long: `Send mails, one for each locale of the recipients.
+
+With --format, the summary is generated for each locale, in the language and
+notations of its recipients. Otherwise every recipient gets the input file.`,
+		Example: `spark mailer ./md/summary-full.md me@example.com
+spark mailer --format today me@example.com grandma@example.com`,
+		Args: cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := c.app.Initialize(); err != nil {
 				return err
 			}
 
-			addresses := args[1:]
+			var input []byte
 
-			md, err := os.ReadFile(args[0])
-			if err != nil {
-				return err
+			if format == "" {
+				if len(args) < 2 {
+					return errors.New("an input file and at least one recipient are needed, or --format")
+				}
+
+				var err error
+
+				if input, err = os.ReadFile(args[0]); err != nil {
+					return err
+				}
+
+				args = args[1:]
 			}
 
-			html, err := markdown.GenerateHTML(md)
+			mails, err := c.app.Config.Mailer.Mails(args, subject, c.app.Now().In(data.LocalTimezone))
 			if err != nil {
 				return err
 			}
 
-			return c.app.Config.Mailer.Send(addresses, subject, string(md), string(html))
+			for _, ml := range mails {
+				md := input
+
+				if format != "" {
+					s, err := c.localSummary(format, ef, ml.To[0])
+					if err != nil {
+						return err
+					}
+
+					md = []byte(s)
+				}
+
+				html, err := markdown.GenerateHTML(md)
+				if err != nil {
+					return err
+				}
+
+				c.app.Config.Mailer.Send(ml, string(md), string(html))
+			}
+
+			return nil
 		},
 	}
 
-	cmd.Flags().StringVar(&subject, "subject", "Daily update", "mail subject")
+	cmd.Flags().StringVar(&subject, "subject", "Daily update", "mail subject; may use {{ .Date }}, {{ .Time }}, {{ .Weekday }} and {{ .Week }}, in the locale of each recipient")
+	cmd.Flags().StringVarP(&format, "format", "f", "", "Generate the summary in this format for each locale, instead of sending an input file")
+	cmd.Flags().UintVarP(&ef.DaysBack, "days-back", "b", 3, "Number of days in the past to include")
+	cmd.Flags().UintVarP(&ef.DaysAhead, "days-ahead", "a", 7, "Number of days in the future to include")
+	cmd.Flags().StringSliceVar(&ef.Tags, "tag", nil, "Only include entries with any of these tags")
+	cmd.Flags().StringSliceVar(&ef.ExcludeTags, "exclude-tag", nil, "Leave out entries with any of these tags")
 
 	return cmd
 }
+
+// localSummary generates the summary of the format in the language and
+// notations of the recipient
+func (c *cli) localSummary(format string, ef app.EntryFilter, recipient string) (string, error) {
+	aiData, err := c.buildData(ef)
+	if err != nil {
+		return "", err
+	}
+
+	if err := c.addOverdueTasks(aiData, format, ef); err != nil {
+		return "", err
+	}
+
+	p, err := c.app.Config.Formats.PromptFor(format)
+	if err != nil {
+		return "", err
+	}
+
+	if err := c.fitBudget(p, aiData, false); err != nil {
+		return "", err
+	}
+
+	aiClient, err := c.app.AIClientFor(c.app.RecipientAssistant(recipient))
+	if err != nil {
+		return "", err
+	}
+
+	ctx := c.app.TrackUsage(context.Background(), "mailer", format)
+
+	md, _, _, err := c.cached(aiClient, p, aiData, false, func() (string, error) {
+		return aiClient.GeneratePrompt(ctx, p, aiData)
+	})
+
+	return md, err
+}
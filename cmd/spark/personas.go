@@ -0,0 +1,214 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/aquasecurity/table"
+	"github.com/jovandeginste/spark-personal-assistant/pkg/app"
+	"github.com/spf13/cobra"
+)
+
+func (c *cli) personasCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "personas",
+		Short: "Manage the personas in the personas directory",
+		Args:  cobra.NoArgs,
+	}
+
+	cmd.AddCommand(c.personasListCmd())
+	cmd.AddCommand(c.personasShowCmd())
+	cmd.AddCommand(c.personasValidateCmd())
+	cmd.AddCommand(c.personasPreviewCmd())
+
+	return cmd
+}
+
+func (c *cli) personasListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List all personas",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ids, err := c.app.PersonaIDs()
+			if err != nil {
+				return err
+			}
+
+			t := table.New(os.Stdout)
+			t.AddHeaders("ID", "Name", "Language", "Provider", "Model", "Valid")
+
+			for _, id := range ids {
+				p, err := c.app.LoadPersona(id)
+				if err != nil {
+					t.AddRow(id, "", "", "", "", "no")
+					continue
+				}
+
+				valid := "yes"
+				if c.app.ValidatePersona(p) != nil {
+					valid = "no"
+				}
+
+				t.AddRow(id, p.Name, p.Language, p.Provider, p.Model, valid)
+			}
+
+			t.Render()
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func (c *cli) personasShowCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "show persona",
+		Short:   "Show the settings and style of a persona",
+		Example: "spark personas show butler",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			p, err := c.app.LoadPersona(args[0])
+			if err != nil {
+				return err
+			}
+
+			temperature := ""
+			if p.Temperature != nil {
+				temperature = strconv.FormatFloat(*p.Temperature, 'g', -1, 64)
+			}
+
+			maxTokens := ""
+			if p.MaxTokens > 0 {
+				maxTokens = strconv.Itoa(p.MaxTokens)
+			}
+
+			t := table.New(os.Stdout)
+			t.AddRow("File", p.File)
+			t.AddRow("Name", p.Name)
+			t.AddRow("Language", p.Language)
+			t.AddRow("Temperature", temperature)
+			t.AddRow("Max tokens", maxTokens)
+			t.AddRow("Provider", p.Provider)
+			t.AddRow("Model", p.Model)
+			t.AddRow("Greeting", p.Greeting)
+			t.Render()
+
+			fmt.Println()
+			fmt.Println(strings.TrimSpace(p.Style))
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func (c *cli) personasValidateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "validate [persona...]",
+		Short:   "Check the personas, or all personas if none are given",
+		Example: "spark personas validate butler chuck",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ids := args
+
+			if len(ids) == 0 {
+				var err error
+
+				if ids, err = c.app.PersonaIDs(); err != nil {
+					return err
+				}
+			}
+
+			invalid := 0
+
+			for _, id := range ids {
+				err := c.validatePersona(id)
+				if err == nil {
+					fmt.Printf("%s: ok\n", id)
+					continue
+				}
+
+				invalid++
+
+				for _, line := range strings.Split(err.Error(), "\n") {
+					fmt.Printf("%s: %s\n", id, line)
+				}
+			}
+
+			if invalid > 0 {
+				return fmt.Errorf("%d of %d personas are invalid", invalid, len(ids))
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func (c *cli) validatePersona(id string) error {
+	p, err := c.app.LoadPersona(id)
+	if err != nil {
+		return err
+	}
+
+	return c.app.ValidatePersona(p)
+}
+
+func (c *cli) personasPreviewCmd() *cobra.Command {
+	var (
+		ef     app.EntryFilter
+		format string
+	)
+
+	cmd := &cobra.Command{
+		Use:     "preview persona",
+		Short:   "Print the prompt for the current entries with a persona, without calling the model",
+		Example: "spark personas preview chuck -f today",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			p, err := c.app.LoadPersona(args[0])
+			if err != nil {
+				return err
+			}
+
+			if err := c.app.ValidatePersona(p); err != nil {
+				return errors.Join(fmt.Errorf("invalid persona %s", p.ID), err)
+			}
+
+			aiData, err := c.buildData(ef)
+			if err != nil {
+				return err
+			}
+
+			if err := c.addOverdueTasks(aiData, format, ef); err != nil {
+				return err
+			}
+
+			prompt, err := c.app.Config.Formats.PromptFor(format)
+			if err != nil {
+				return err
+			}
+
+			aiClient, err := c.app.AIClientFor(p.AssistantConfig)
+			if err != nil {
+				return err
+			}
+
+			return c.printRequest(aiClient, prompt, aiData)
+		},
+	}
+
+	cmd.Flags().StringVarP(&format, "format", "f", "today", "Format to use: today, week, full, custom or a format from the config")
+	cmd.Flags().UintVarP(&ef.DaysBack, "days-back", "b", 3, "Number of days in the past to include")
+	cmd.Flags().UintVarP(&ef.DaysAhead, "days-ahead", "a", 7, "Number of days in the future to include")
+	cmd.Flags().StringSliceVar(&ef.Tags, "tag", nil, "Only include entries with any of these tags")
+	cmd.Flags().StringSliceVar(&ef.ExcludeTags, "exclude-tag", nil, "Leave out entries with any of these tags")
+
+	return cmd
+}
@@ -2,9 +2,12 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"slices"
 	"strings"
 
 	"github.com/chzyer/readline"
@@ -15,23 +18,39 @@ import (
 	"github.com/yarlson/pin"
 )
 
-type ChatHistory struct {
-	Role    string
-	Content string
-}
 type AIData struct {
 	ExtraContext     []string
-	ChatHistory      []ChatHistory `json:",omitempty"`
-	EmployerQuestion []string      `json:",omitempty"`
+	EmployerQuestion []string `json:",omitempty"`
 	UserData         app.UserData
 	Entries          data.Entries
+	OverdueTasks     data.Entries `json:",omitempty"`
+}
+
+// EntryIDs returns the IDs of the entries and overdue tasks, which a
+// structured summary may reference
+func (d *AIData) EntryIDs() []uint64 {
+	ids := make([]uint64, 0, len(d.Entries)+len(d.OverdueTasks))
+
+	for _, es := range []data.Entries{d.Entries, d.OverdueTasks} {
+		for _, e := range es {
+			ids = append(ids, e.ID)
+		}
+	}
+
+	return ids
 }
 
 func (c *cli) printCmd() *cobra.Command {
 	var (
-		ef           app.EntryFilter
-		format       string
-		customPrompt []string
+		ef            app.EntryFilter
+		format        string
+		customPrompt  []string
+		explainBudget bool
+		noCache       bool
+		structured    bool
+		jsonOutput    bool
+		dryRun        bool
+		retrieve      bool
 	)
 
 	cmd := &cobra.Command{
@@ -51,16 +70,42 @@ func (c *cli) printCmd() *cobra.Command {
 
 			aiData.EmployerQuestion = customPrompt
 
-			p, err := ai.PromptFor(format)
+			if err := c.addOverdueTasks(aiData, format, ef); err != nil {
+				return err
+			}
+
+			if retrieve {
+				if len(customPrompt) == 0 {
+					return errors.New("--retrieve needs a question in --prompt")
+				}
+
+				if err := c.retrieveEntries(c.app.TrackUsage(context.Background(), "print", format), aiData, strings.Join(customPrompt, "\n"), ef); err != nil {
+					return err
+				}
+			}
+
+			p, err := c.app.Config.Formats.PromptFor(format)
 			if err != nil {
 				return err
 			}
 
-			aiClient, err := ai.NewClient(c.app.Config.LLM, c.app.Config.Assistant)
+			if err := c.fitBudget(p, aiData, explainBudget); err != nil {
+				return err
+			}
+
+			if structured || jsonOutput {
+				p = ai.StructuredPrompt(p)
+			}
+
+			aiClient, err := c.app.AIClient()
 			if err != nil {
 				return err
 			}
 
+			if dryRun {
+				return c.printRequest(aiClient, p, aiData)
+			}
+
 			c.app.Logger().Info(
 				"Generating summary for entries...",
 				"type", c.app.Config.LLM.Type,
@@ -68,38 +113,43 @@ func (c *cli) printCmd() *cobra.Command {
 				"name", c.app.Config.Assistant.Name,
 			)
 
-			spinner := pin.New("Thinking...",
-				pin.WithSpinnerColor(pin.ColorCyan),
-				pin.WithTextColor(pin.ColorYellow),
-				pin.WithWriter(os.Stderr),
-			)
-			cancel := spinner.Start(context.Background())
-			defer cancel()
+			ctx := c.app.TrackUsage(context.Background(), "print", format)
 
-			md, err := aiClient.GeneratePrompt(context.Background(), p, aiData)
-			if err != nil {
-				return err
+			if structured || jsonOutput {
+				return c.printStructured(ctx, aiClient, p, aiData, noCache, jsonOutput)
 			}
 
-			spinner.Stop("Ready!")
-			fmt.Println(md)
+			_, err = c.generateCached(ctx, aiClient, p, aiData, noCache)
 
-			return nil
+			return err
 		},
 	}
 
 	cmd.Flags().StringSliceVarP(&customPrompt, "prompt", "p", nil, "extra custom prompt")
 	cmd.Flags().StringVar(&c.app.ConfigFile, "config", "./spark.yaml", "config file")
-	cmd.Flags().StringVar(&c.app.Config.AssistantFileCLI, "persona", "", "persona")
-	cmd.Flags().StringVarP(&format, "format", "f", "full", "Format to use")
+	cmd.Flags().StringVar(&c.app.Config.AssistantFileCLI, "persona", "", "Persona from the personas directory, or the path to a persona file")
+	cmd.Flags().StringVar(&c.app.Config.RecipientCLI, "recipient", "", "Use the language and notations of this recipient")
+	cmd.Flags().StringVarP(&format, "format", "f", "full", "Format to use: today, week, full, custom or a format from the config")
 	cmd.Flags().UintVarP(&ef.DaysBack, "days-back", "b", 3, "Number of days in the past to include")
 	cmd.Flags().UintVarP(&ef.DaysAhead, "days-ahead", "a", 7, "Number of days in the future to include")
+	cmd.Flags().StringSliceVar(&ef.Tags, "tag", nil, "Only include entries with any of these tags")
+	cmd.Flags().StringSliceVar(&ef.ExcludeTags, "exclude-tag", nil, "Leave out entries with any of these tags")
+	cmd.Flags().BoolVar(&explainBudget, "explain-budget", false, "Show which entries were trimmed to fit the token budget")
+	cmd.Flags().BoolVar(&retrieve, "retrieve", false, "Use the entries that are most relevant to --prompt instead of the date window")
+	cmd.Flags().BoolVar(&noCache, "no-cache", false, "Ignore cached responses and generate a new summary")
+	cmd.Flags().BoolVar(&structured, "structured", false, "Ask for a structured response and render the Markdown locally")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Ask for a structured response and print it as JSON")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the prompt as it would be sent to the model, part by part, instead of sending it")
 
 	return cmd
 }
 
 func (c *cli) chatCmd() *cobra.Command {
-	var ef app.EntryFilter
+	var (
+		ef       app.EntryFilter
+		tools    bool
+		retrieve bool
+	)
 
 	cmd := &cobra.Command{
 		Use:     "chat",
@@ -116,12 +166,23 @@ func (c *cli) chatCmd() *cobra.Command {
 				return err
 			}
 
-			p, err := ai.PromptFor("custom")
+			if err := c.fitBudget(ai.PromptCustom, aiData, false); err != nil {
+				return err
+			}
+
+			conv, err := ai.NewConversation(c.app.Assistant(), aiData)
 			if err != nil {
 				return err
 			}
 
-			aiClient, err := ai.NewClient(c.app.Config.LLM, c.app.Config.Assistant)
+			if tools {
+				conv.Tools = c.app.ChatTools()
+				conv.System = append(conv.System,
+					"Use the tools to look up entries that are not in the context, and to create entries when asked.",
+				)
+			}
+
+			aiClient, err := c.app.AIClient()
 			if err != nil {
 				return err
 			}
@@ -133,6 +194,8 @@ func (c *cli) chatCmd() *cobra.Command {
 				"name", c.app.Config.Assistant.Name,
 			)
 
+			ctx := c.app.TrackUsage(context.Background(), "chat", "")
+
 			rl, err := readline.New("> ")
 			if err != nil {
 				return err
@@ -140,7 +203,7 @@ func (c *cli) chatCmd() *cobra.Command {
 
 			defer rl.Close() // Ensure readline resources are cleaned up when the program exits
 
-			fmt.Println("Enter your question. Type /quit to exit or press Ctrl+D.")
+			fmt.Println("Enter your question. Type /prompt to show the prompt, /quit to exit or press Ctrl+D.")
 
 		input:
 			for {
@@ -166,32 +229,31 @@ func (c *cli) chatCmd() *cobra.Command {
 				case "/quit":
 					fmt.Println("Goodbye!")
 					break input
+				case "/prompt":
+					c.printPrompt(conv.Text())
+					continue
 				}
 
-				aiData.EmployerQuestion = []string{input}
+				if retrieve {
+					if err := c.retrieveEntries(ctx, aiData, input, ef); err != nil {
+						return err
+					}
+
+					if err := conv.SetContext(aiData); err != nil {
+						return err
+					}
+				}
+
+				conv.AddUser(input)
 
 				c.app.Logger().Info("Parsing your question...")
-				spinner := pin.New("Thinking...",
-					pin.WithSpinnerColor(pin.ColorCyan),
-					pin.WithTextColor(pin.ColorYellow),
-					pin.WithWriter(os.Stderr),
-				)
-				cancel := spinner.Start(context.Background())
-				defer cancel()
 
-				md, err := aiClient.GeneratePrompt(context.Background(), p, aiData)
+				md, err := c.streamChat(ctx, aiClient, conv)
 				if err != nil {
 					return err
 				}
 
-				spinner.Stop("Ready!")
-				fmt.Println(md)
-
-				aiData.ChatHistory = append(
-					aiData.ChatHistory,
-					ChatHistory{Role: "user", Content: input},
-					ChatHistory{Role: "assistant", Content: md},
-				)
+				conv.AddAssistant(md)
 			}
 
 			return nil
@@ -199,13 +261,280 @@ func (c *cli) chatCmd() *cobra.Command {
 	}
 
 	cmd.Flags().StringVar(&c.app.ConfigFile, "config", "./spark.yaml", "config file")
-	cmd.Flags().StringVar(&c.app.Config.AssistantFileCLI, "persona", "", "persona")
+	cmd.Flags().StringVar(&c.app.Config.AssistantFileCLI, "persona", "", "Persona from the personas directory, or the path to a persona file")
+	cmd.Flags().StringVar(&c.app.Config.RecipientCLI, "recipient", "", "Use the language and notations of this recipient")
 	cmd.Flags().UintVarP(&ef.DaysBack, "days-back", "b", 3, "Number of days in the past to include")
 	cmd.Flags().UintVarP(&ef.DaysAhead, "days-ahead", "a", 7, "Number of days in the future to include")
+	cmd.Flags().StringSliceVar(&ef.Tags, "tag", nil, "Only include entries with any of these tags")
+	cmd.Flags().StringSliceVar(&ef.ExcludeTags, "exclude-tag", nil, "Leave out entries with any of these tags")
+	cmd.Flags().BoolVar(&tools, "tools", true, "Allow Spark to query and create entries while chatting")
+	cmd.Flags().BoolVar(&retrieve, "retrieve", false, "Use the entries that are most relevant to each question instead of the date window")
 
 	return cmd
 }
 
+// streamPrompt shows a spinner until the first chunk arrives, then prints the
+// response as it is generated
+func (c *cli) streamPrompt(ctx context.Context, aiClient ai.Client, p ai.Prompt, aiData *AIData) (string, error) {
+	return c.stream(ctx, func(fn ai.StreamFunc) (string, error) {
+		return aiClient.StreamPrompt(ctx, p, aiData, fn)
+	})
+}
+
+// generateCached prints the cached response to the prompt if there is one,
+// and generates and caches a new response otherwise
+func (c *cli) generateCached(ctx context.Context, aiClient *ai.Chain, p ai.Prompt, aiData *AIData, noCache bool) (string, error) {
+	md, _, hit, err := c.cached(aiClient, p, aiData, noCache, func() (string, error) {
+		return c.generate(ctx, aiClient, p, aiData)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if hit {
+		fmt.Println(md)
+	}
+
+	return md, nil
+}
+
+// printStructured asks for a summary that matches the schema, validates it
+// and prints it as JSON or as Markdown rendered from the summary template
+func (c *cli) printStructured(ctx context.Context, aiClient *ai.Chain, p ai.Prompt, aiData *AIData, noCache, jsonOutput bool) error {
+	response, producer, _, err := c.cached(aiClient, p, aiData, noCache, func() (string, error) {
+		j, err := aiClient.GenerateJSON(ctx, p, aiData, ai.SummarySchema)
+		if err != nil {
+			return "", err
+		}
+
+		c.app.Logger().Info("Summary generated", "type", aiClient.Used().Type, "model", aiClient.Used().Model)
+
+		return j, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	summary, err := ai.ParseSummary(response, aiData.EntryIDs())
+	if err != nil {
+		return err
+	}
+
+	summary.Provider, summary.Model = producer.Type, producer.Model
+
+	if jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(summary)
+	}
+
+	md, err := summary.Render(c.app.Config.SummaryTemplate)
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(md)
+
+	return nil
+}
+
+// cached returns the cached response to the prompt if there is one, and
+// generates and caches a new response otherwise, along with the LLM that
+// produced it
+func (c *cli) cached(aiClient *ai.Chain, p ai.Prompt, aiData *AIData, noCache bool, generate func() (string, error)) (string, *ai.AIConfig, bool, error) {
+	if c.app.Config.Cache.Disabled {
+		response, err := generate()
+		return response, aiClient.Used(), false, err
+	}
+
+	prompt, err := p(aiClient.Assistant(), aiData)
+	if err != nil {
+		return "", nil, false, err
+	}
+
+	hash := c.app.CacheHash(aiClient.Primary(), prompt)
+
+	if !noCache {
+		r, err := c.app.CachedResponse(hash)
+		if err != nil {
+			return "", nil, false, err
+		}
+
+		if r != nil {
+			c.app.Logger().Info("Using cached response", "type", r.Provider, "model", r.Model, "created_at", r.CreatedAt)
+
+			return r.Response, &ai.AIConfig{Type: r.Provider, Model: r.Model}, true, nil
+		}
+	}
+
+	response, err := generate()
+	if err != nil {
+		return "", nil, false, err
+	}
+
+	return response, aiClient.Used(), false, c.app.CacheResponse(hash, response, aiClient.Used())
+}
+
+// generate streams a new response and logs which LLM produced it
+func (c *cli) generate(ctx context.Context, aiClient *ai.Chain, p ai.Prompt, aiData *AIData) (string, error) {
+	md, err := c.streamPrompt(ctx, aiClient, p, aiData)
+	if err != nil {
+		return "", err
+	}
+
+	c.app.Logger().Info("Summary generated", "type", aiClient.Used().Type, "model", aiClient.Used().Model)
+
+	return md, nil
+}
+
+// streamChat is streamPrompt for the next turn in a conversation
+func (c *cli) streamChat(ctx context.Context, aiClient ai.Client, conv *ai.Conversation) (string, error) {
+	return c.stream(ctx, func(fn ai.StreamFunc) (string, error) {
+		return aiClient.StreamChat(ctx, conv, fn)
+	})
+}
+
+func (c *cli) stream(ctx context.Context, generate func(ai.StreamFunc) (string, error)) (string, error) {
+	spinner := pin.New("Thinking...",
+		pin.WithSpinnerColor(pin.ColorCyan),
+		pin.WithTextColor(pin.ColorYellow),
+		pin.WithWriter(os.Stderr),
+	)
+	cancel := spinner.Start(ctx)
+	defer cancel()
+
+	md, err := generate(func(chunk string) error {
+		spinner.Stop("Ready!")
+		fmt.Print(chunk)
+
+		return nil
+	})
+	if err != nil {
+		spinner.Fail("Failed!")
+		return "", err
+	}
+
+	spinner.Stop("Ready!")
+	fmt.Println()
+
+	return md, nil
+}
+
+// printRequest prints the prompt as the first provider sends it, part by part
+// with the role of each part
+func (c *cli) printRequest(aiClient ai.Client, p ai.Prompt, data any) error {
+	parts, err := aiClient.RenderPrompt(p, data)
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+
+	for _, part := range parts {
+		fmt.Fprintf(&b, "[%s]\n%s\n\n", part.Role, part.Text)
+	}
+
+	c.printPrompt(b.String())
+
+	return nil
+}
+
+// printPrompt prints the prompt exactly as it would be sent, followed by its
+// size on stderr
+func (c *cli) printPrompt(prompt string) {
+	fmt.Print(prompt)
+
+	tokens := 0
+	if llm := c.app.Config.LLM; llm != nil {
+		tokens = llm.EstimateTokens(prompt)
+	}
+
+	fmt.Fprintf(os.Stderr, "Estimated size: %d tokens, %d bytes\n", tokens, len(prompt))
+}
+
+// fitBudget trims the entries until the estimated size of the prompt fits the
+// token budget of the LLM
+func (c *cli) fitBudget(p ai.Prompt, aiData *AIData, explain bool) error {
+	llm := c.app.Config.LLM
+	if llm == nil {
+		return nil
+	}
+
+	budget := llm.InputBudget()
+	if budget == 0 {
+		return nil
+	}
+
+	var estimates []int
+
+	entries, report, err := aiData.Entries.Trim(c.app.Now(), func(es data.Entries) (bool, error) {
+		aiData.Entries = es
+
+		tokens, err := llm.PromptTokens(p, c.app.Assistant(), aiData)
+		estimates = append(estimates, tokens)
+
+		return tokens <= budget, err
+	})
+	if err != nil {
+		return err
+	}
+
+	aiData.Entries = entries
+
+	if !report.Fits {
+		c.app.Logger().Warn("Prompt exceeds the token budget after trimming", "tokens", estimates[len(estimates)-1], "budget", budget)
+	}
+
+	if explain {
+		fmt.Fprintf(os.Stderr, "Token budget: %d, estimated prompt size: %d tokens\n", budget, estimates[0])
+		report.PrintTo(os.Stderr)
+		fmt.Fprintf(os.Stderr, "Estimated prompt size after trimming: %d tokens\n", estimates[len(estimates)-1])
+	}
+
+	return nil
+}
+
+// retrieveEntries replaces the entries with those of the source and tags of
+// the filter that are most relevant to the query
+func (c *cli) retrieveEntries(ctx context.Context, aiData *AIData, query string, ef app.EntryFilter) error {
+	aiClient, err := c.app.AIClient()
+	if err != nil {
+		return err
+	}
+
+	entries, err := c.app.RelevantEntries(ctx, aiClient, query, ef)
+	if err != nil {
+		return err
+	}
+
+	aiData.Entries = entries
+
+	return nil
+}
+
+// addOverdueTasks adds the overdue tasks of the source and tags of the filter
+// that are not in the entries yet, if the format includes them, so they do
+// not drop off the summary
+func (c *cli) addOverdueTasks(aiData *AIData, format string, ef app.EntryFilter) error {
+	if !c.app.Config.Formats.Overdue(format) {
+		return nil
+	}
+
+	tasks, err := c.app.OverdueTasks(ef)
+	if err != nil {
+		return err
+	}
+
+	for _, t := range tasks {
+		if !slices.ContainsFunc(aiData.Entries, func(e data.Entry) bool { return e.ID == t.ID }) {
+			aiData.OverdueTasks = append(aiData.OverdueTasks, t)
+		}
+	}
+
+	return nil
+}
+
 func (c *cli) buildData(ef app.EntryFilter) (*AIData, error) {
 	entries, err := c.app.CurrentEntries(ef)
 	if err != nil {
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/jovandeginste/spark-personal-assistant/pkg/ai"
+	"github.com/jovandeginste/spark-personal-assistant/pkg/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAIData_EntryIDs(t *testing.T) {
+	aiData := &AIData{
+		Entries:      data.Entries{{ID: 3}, {ID: 5}},
+		OverdueTasks: data.Entries{{ID: 1}},
+	}
+
+	assert.Equal(t, []uint64{3, 5, 1}, aiData.EntryIDs())
+
+	_, err := ai.ParseSummary(`{
+		"greeting": "Good morning, sir.",
+		"schedule": [{"day": "Today", "time": "", "title": "Renew passport", "details": "Overdue", "entries": [1]}],
+		"todos": [], "reminders": [], "weather": ""
+	}`, aiData.EntryIDs())
+	require.NoError(t, err, "Overdue tasks may be referenced")
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/jovandeginste/spark-personal-assistant/pkg/data"
+	"github.com/spf13/cobra"
+)
+
+func (c *cli) tasksCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tasks",
+		Short: "Manage tasks",
+		Args:  cobra.NoArgs,
+	}
+
+	cmd.AddCommand(c.addTaskCmd())
+	cmd.AddCommand(c.listTasksCmd())
+	cmd.AddCommand(c.doneTaskCmd())
+	cmd.AddCommand(c.cancelTaskCmd())
+
+	return cmd
+}
+
+func (c *cli) addTaskCmd() *cobra.Command {
+	var (
+		e    data.Entry
+		d    string
+		due  string
+		i    string
+		s    string
+		tags []string
+	)
+
+	cmd := &cobra.Command{
+		Use:     "add",
+		Short:   "Add a task",
+		Example: `spark tasks add -t "Renew the passport" --due 2025-09-01`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			src, err := c.app.FindSourceByName(s)
+			if err != nil {
+				return err
+			}
+
+			e.Source = src
+			e.Status = data.OPEN
+
+			if err := e.SetDate(d, c.app.Now()); err != nil {
+				return err
+			}
+
+			if err := e.SetDue(due, c.app.Now()); err != nil {
+				return err
+			}
+
+			if err := e.SetImportance(i); err != nil {
+				return err
+			}
+
+			e.AddTags(tags...)
+
+			if err := c.app.CreateEntry(&e); err != nil {
+				return err
+			}
+
+			c.app.Logger().Info("Task added")
+			e.PrintTo(os.Stdout)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&e.Summary, "title", "t", "", "Title of the task")
+	cmd.Flags().StringVarP(&i, "importance", "i", string(data.MEDIUM), "Importance of the task")
+	cmd.Flags().StringVarP(&d, "date", "d", "", "Date of the task; defaults to today")
+	cmd.Flags().StringVar(&due, "due", "", "Due date of the task, eg. 2025-09-01 or in 2 weeks")
+	cmd.Flags().StringVarP(&s, "source", "s", "manual", "Source of the task")
+	cmd.Flags().StringSliceVar(&tags, "tag", nil, "Tags of the task, eg. kids,school")
+
+	return cmd
+}
+
+func (c *cli) listTasksCmd() *cobra.Command {
+	var all bool
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the open tasks, the first due first",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			tasks, err := c.app.Tasks(all)
+			if err != nil {
+				return err
+			}
+
+			tasks.PrintTasksTo(os.Stdout, c.app.Now())
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&all, "all", false, "Include the done and cancelled tasks")
+
+	return cmd
+}
+
+func (c *cli) doneTaskCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "done id...",
+		Short: "Mark tasks as done",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.setTaskStatus(args, data.DONE)
+		},
+	}
+
+	return cmd
+}
+
+func (c *cli) cancelTaskCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cancel id...",
+		Short: "Cancel tasks",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.setTaskStatus(args, data.CANCELLED)
+		},
+	}
+
+	return cmd
+}
+
+func (c *cli) setTaskStatus(args []string, status data.TaskStatus) error {
+	for _, arg := range args {
+		id, err := strconv.ParseUint(arg, 10, 64)
+		if err != nil {
+			return err
+		}
+
+		e := &data.Entry{ID: id}
+
+		if err := c.app.FindEntry(e); err != nil {
+			return err
+		}
+
+		if !e.IsTask() {
+			return fmt.Errorf("entry %d is not a task", id)
+		}
+
+		if err := e.SetStatus(string(status), c.app.Now()); err != nil {
+			return err
+		}
+
+		if err := c.app.UpdateEntry(e); err != nil {
+			return err
+		}
+
+		c.app.Logger().Info("Task updated", "task", e.Summary, "status", e.Status)
+	}
+
+	return nil
+}
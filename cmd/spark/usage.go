@@ -0,0 +1,38 @@
+package main
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+func (c *cli) usageCmd() *cobra.Command {
+	var (
+		days    uint
+		monthly bool
+	)
+
+	cmd := &cobra.Command{
+		Use:     "usage",
+		Short:   "Show LLM token usage and estimated cost",
+		Example: "spark usage --monthly --days 365",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			since := c.app.Now().AddDate(0, 0, -int(days))
+
+			report, err := c.app.UsageReport(since, monthly)
+			if err != nil {
+				return err
+			}
+
+			report.PrintTo(os.Stdout)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().UintVarP(&days, "days", "d", 30, "Number of days to include")
+	cmd.Flags().BoolVarP(&monthly, "monthly", "m", false, "Show monthly instead of daily totals")
+
+	return cmd
+}
@@ -19,7 +19,7 @@ func (c *cli) vcfCmd() *cobra.Command {
 
 			file := args[1]
 
-			entries, err := vcf.BuildEntriesFromFile(file)
+			entries, err := vcf.BuildEntriesFromFile(file, c.app.Now())
 			if err != nil {
 				return err
 			}
@@ -0,0 +1,553 @@
+package ai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+const (
+	anthropicBaseURL   = "https://api.anthropic.com"
+	anthropicVersion   = "2023-06-01"
+	anthropicMaxTokens = 4096
+)
+
+type anthropicClient struct {
+	apiKey      string
+	model       string
+	baseURL     string
+	headers     map[string]string
+	maxTokens   int
+	temperature *float64
+	assistant   AssistantConfig
+}
+
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content any    `json:"content"` // a string or a list of anthropicContent
+}
+
+// anthropicContent is a content block: text, a tool call or a tool result
+type anthropicContent struct {
+	Type      string          `json:"type"`
+	Text      string          `json:"text,omitempty"`
+	ID        string          `json:"id,omitempty"`
+	Name      string          `json:"name,omitempty"`
+	Input     json.RawMessage `json:"input,omitempty"`
+	ToolUseID string          `json:"tool_use_id,omitempty"`
+	Content   string          `json:"content,omitempty"`
+}
+
+type anthropicTool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	InputSchema map[string]any `json:"input_schema"`
+}
+
+type anthropicToolChoice struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
+}
+
+type anthropicRequest struct {
+	Model       string               `json:"model"`
+	MaxTokens   int                  `json:"max_tokens"`
+	System      string               `json:"system,omitempty"`
+	Messages    []anthropicMessage   `json:"messages"`
+	Tools       []anthropicTool      `json:"tools,omitempty"`
+	ToolChoice  *anthropicToolChoice `json:"tool_choice,omitempty"`
+	Temperature *float64             `json:"temperature,omitempty"`
+	Stream      bool                 `json:"stream,omitempty"`
+}
+
+type anthropicError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+type anthropicUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+type anthropicResponse struct {
+	Content []anthropicContent `json:"content"`
+	Usage   anthropicUsage     `json:"usage"`
+	Error   *anthropicError    `json:"error,omitempty"`
+}
+
+type anthropicEvent struct {
+	Type         string           `json:"type"`
+	Index        int              `json:"index"`
+	ContentBlock anthropicContent `json:"content_block"`
+	Delta        struct {
+		Type        string `json:"type"`
+		Text        string `json:"text"`
+		PartialJSON string `json:"partial_json"`
+	} `json:"delta"`
+	Message anthropicResponse `json:"message"` // message_start
+	Usage   anthropicUsage    `json:"usage"`   // message_delta
+	Error   *anthropicError   `json:"error,omitempty"`
+}
+
+func newAnthropicClient(cc *AIConfig, ac AssistantConfig) anthropicClient {
+	c := anthropicClient{
+		apiKey:      cc.APIKey,
+		model:       cc.Model,
+		baseURL:     cc.BaseURL,
+		headers:     cc.Headers,
+		maxTokens:   cc.MaxTokens,
+		temperature: cc.Temperature,
+		assistant:   ac,
+	}
+
+	if c.baseURL == "" {
+		c.baseURL = anthropicBaseURL
+	}
+
+	if c.maxTokens == 0 {
+		c.maxTokens = anthropicMaxTokens
+	}
+
+	return c
+}
+
+// EmbeddingModel is empty, since Anthropic has no embeddings
+func (c anthropicClient) EmbeddingModel() string {
+	return ""
+}
+
+func (c anthropicClient) Embed(context.Context, []string) ([][]float32, error) {
+	return nil, ErrNoEmbeddings
+}
+
+func (c anthropicClient) APIKey() string {
+	return c.apiKey
+}
+
+func (c anthropicClient) Model() string {
+	return c.model
+}
+
+// convertPrompt moves the persona's preamble to the system prompt and sends
+// the remainder of the prompt as the user message
+func (c anthropicClient) convertPrompt(p Prompt, data any) (*anthropicRequest, error) {
+	prompt, err := p(c.assistant, data)
+	if err != nil {
+		return nil, err
+	}
+
+	preamble := c.assistant.PromptPreamble()
+
+	var system []string
+
+	if len(prompt) >= len(preamble) && slices.Equal(prompt[:len(preamble)], preamble) {
+		system, prompt = preamble, prompt[len(preamble):]
+	}
+
+	return c.request(strings.Join(system, "\n"), []anthropicMessage{
+		{Role: "user", Content: strings.Join(prompt, "\n")},
+	}), nil
+}
+
+func (c anthropicClient) convertConversation(conv *Conversation) *anthropicRequest {
+	messages := make([]anthropicMessage, 0, len(conv.Messages))
+
+	for _, m := range conv.Messages {
+		messages = append(messages, anthropicMessage{Role: string(m.Role), Content: m.Content})
+	}
+
+	system := append(slices.Clone(conv.System), conv.Context)
+
+	return c.request(strings.Join(system, "\n"), messages)
+}
+
+func (c anthropicClient) RenderPrompt(p Prompt, data any) ([]Part, error) {
+	req, err := c.convertPrompt(p, data)
+	if err != nil {
+		return nil, err
+	}
+
+	var parts []Part
+
+	if req.System != "" {
+		parts = append(parts, Part{Role: "system", Text: req.System})
+	}
+
+	for _, m := range req.Messages {
+		parts = append(parts, Part{Role: m.Role, Text: fmt.Sprint(m.Content)})
+	}
+
+	return parts, nil
+}
+
+func (c anthropicClient) request(system string, messages []anthropicMessage) *anthropicRequest {
+	return &anthropicRequest{
+		Model:       c.Model(),
+		MaxTokens:   c.maxTokens,
+		System:      system,
+		Messages:    messages,
+		Temperature: c.temperature,
+	}
+}
+
+func (c anthropicClient) GeneratePrompt(ctx context.Context, p Prompt, data any) (string, error) {
+	req, err := c.convertPrompt(p, data)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := c.do(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var result anthropicResponse
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", err
+	}
+
+	c.reportUsage(ctx, result.Usage)
+
+	return anthropicText(result.Content), nil
+}
+
+// GenerateJSON forces the model to call a tool with the schema as its input;
+// the Messages API has no other way to enforce a schema
+func (c anthropicClient) GenerateJSON(ctx context.Context, p Prompt, data any, schema *Schema) (string, error) {
+	req, err := c.convertPrompt(p, data)
+	if err != nil {
+		return "", err
+	}
+
+	req.Tools = []anthropicTool{{
+		Name:        "respond",
+		Description: "Respond to the prompt",
+		InputSchema: schema.JSONSchema(),
+	}}
+	req.ToolChoice = &anthropicToolChoice{Type: "tool", Name: "respond"}
+
+	resp, err := c.do(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var result anthropicResponse
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", err
+	}
+
+	c.reportUsage(ctx, result.Usage)
+
+	for _, b := range result.Content {
+		if b.Type == "tool_use" {
+			return string(b.Input), nil
+		}
+	}
+
+	return "", errors.New("anthropic response did not contain the structured response")
+}
+
+func (c anthropicClient) StreamPrompt(ctx context.Context, p Prompt, data any, fn StreamFunc) (string, error) {
+	req, err := c.convertPrompt(p, data)
+	if err != nil {
+		return "", err
+	}
+
+	blocks, err := c.stream(ctx, req, fn)
+	if err != nil {
+		return "", err
+	}
+
+	return anthropicText(blocks), nil
+}
+
+func (c anthropicClient) convertTools(tools []Tool) []anthropicTool {
+	result := make([]anthropicTool, 0, len(tools))
+
+	for _, t := range tools {
+		result = append(result, anthropicTool{
+			Name:        t.Name,
+			Description: t.Description,
+			InputSchema: t.JSONSchema(),
+		})
+	}
+
+	return result
+}
+
+func (c anthropicClient) StreamChat(ctx context.Context, conv *Conversation, fn StreamFunc) (string, error) {
+	req := c.convertConversation(conv)
+	req.Tools = c.convertTools(conv.Tools)
+
+	var result strings.Builder
+
+	for range maxToolRounds {
+		blocks, err := c.stream(ctx, req, fn)
+		if err != nil {
+			return "", err
+		}
+
+		result.WriteString(anthropicText(blocks))
+
+		var toolResults []anthropicContent
+
+		for _, b := range blocks {
+			if b.Type != "tool_use" {
+				continue
+			}
+
+			var args map[string]any
+
+			var content string
+
+			if err := json.Unmarshal(b.Input, &args); err != nil {
+				content = toolError(err)
+			} else {
+				content = conv.callTool(ctx, b.Name, args)
+			}
+
+			toolResults = append(toolResults, anthropicContent{Type: "tool_result", ToolUseID: b.ID, Content: content})
+		}
+
+		if len(toolResults) == 0 {
+			return result.String(), nil
+		}
+
+		req.Messages = append(req.Messages,
+			anthropicMessage{Role: "assistant", Content: blocks},
+			anthropicMessage{Role: "user", Content: toolResults},
+		)
+	}
+
+	return "", ErrTooManyToolCalls
+}
+
+// stream passes every chunk of text to fn and returns all content blocks of
+// the response, including tool calls
+func (c anthropicClient) stream(ctx context.Context, req *anthropicRequest, fn StreamFunc) ([]anthropicContent, error) {
+	req.Stream = true
+
+	resp, err := c.do(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var (
+		blocks []anthropicContent
+		inputs = map[int]*strings.Builder{}
+		usage  anthropicUsage
+	)
+
+	defer func() { c.reportUsage(ctx, usage) }()
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+
+		var event anthropicEvent
+
+		if err := json.Unmarshal([]byte(strings.TrimSpace(line)), &event); err != nil {
+			return nil, err
+		}
+
+		switch event.Type {
+		case "error":
+			return nil, anthropicStatusError{StatusCode: event.Error.statusCode(), Err: anthropicErr(event.Error)}
+		case "message_start":
+			usage.InputTokens = event.Message.Usage.InputTokens
+		case "message_delta":
+			usage.OutputTokens = event.Usage.OutputTokens
+		case "message_stop":
+			return finishAnthropicBlocks(blocks, inputs), nil
+		case "content_block_start":
+			for len(blocks) <= event.Index {
+				blocks = append(blocks, anthropicContent{})
+			}
+
+			blocks[event.Index] = event.ContentBlock
+		case "content_block_delta":
+			for len(blocks) <= event.Index {
+				blocks = append(blocks, anthropicContent{Type: "text"})
+			}
+
+			switch event.Delta.Type {
+			case "input_json_delta":
+				if inputs[event.Index] == nil {
+					inputs[event.Index] = &strings.Builder{}
+				}
+
+				inputs[event.Index].WriteString(event.Delta.PartialJSON)
+			case "text_delta":
+				if event.Delta.Text == "" {
+					continue
+				}
+
+				blocks[event.Index].Text += event.Delta.Text
+
+				if err := fn(event.Delta.Text); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return finishAnthropicBlocks(blocks, inputs), nil
+}
+
+// finishAnthropicBlocks sets the streamed input of the tool calls and drops
+// empty text blocks, which the API does not accept in follow-up requests
+func finishAnthropicBlocks(blocks []anthropicContent, inputs map[int]*strings.Builder) []anthropicContent {
+	result := make([]anthropicContent, 0, len(blocks))
+
+	for i, b := range blocks {
+		switch b.Type {
+		case "text":
+			if b.Text == "" {
+				continue
+			}
+		case "tool_use":
+			b.Input = json.RawMessage("{}")
+
+			if in, ok := inputs[i]; ok && in.Len() > 0 {
+				b.Input = json.RawMessage(in.String())
+			}
+		}
+
+		result = append(result, b)
+	}
+
+	return result
+}
+
+func anthropicText(blocks []anthropicContent) string {
+	var text strings.Builder
+
+	for _, b := range blocks {
+		if b.Type == "text" {
+			text.WriteString(b.Text)
+		}
+	}
+
+	return text.String()
+}
+
+func (c anthropicClient) do(ctx context.Context, body *anthropicRequest) (*http.Response, error) {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(c.baseURL, "/")+"/v1/messages", bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Api-Key", c.apiKey)
+	req.Header.Set("Anthropic-Version", anthropicVersion)
+
+	for k, v := range c.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+
+		var result anthropicResponse
+
+		statusErr := anthropicStatusError{StatusCode: resp.StatusCode, Status: resp.Status}
+
+		msg, _ := io.ReadAll(resp.Body)
+		if err := json.Unmarshal(msg, &result); err == nil && result.Error != nil {
+			statusErr.Err = anthropicErr(result.Error)
+		}
+
+		return nil, statusErr
+	}
+
+	return resp, nil
+}
+
+func (c anthropicClient) reportUsage(ctx context.Context, u anthropicUsage) {
+	ReportUsage(ctx, Usage{
+		Provider:         "anthropic",
+		Model:            c.Model(),
+		PromptTokens:     u.InputTokens,
+		CompletionTokens: u.OutputTokens,
+	})
+}
+
+// anthropicStatusError is an error response of the API; errors in the event
+// stream have no HTTP status, so it is derived from the type of the error
+type anthropicStatusError struct {
+	StatusCode int
+	Status     string
+	Err        error
+}
+
+func (e anthropicStatusError) Error() string {
+	switch {
+	case e.Err == nil:
+		return "anthropic request failed with status: " + e.Status
+	case e.Status == "":
+		return e.Err.Error()
+	default:
+		return fmt.Sprintf("anthropic request failed with status %s: %s", e.Status, e.Err)
+	}
+}
+
+func (e anthropicStatusError) Unwrap() error {
+	return e.Err
+}
+
+func (e *anthropicError) statusCode() int {
+	if e == nil {
+		return 0
+	}
+
+	switch e.Type {
+	case "rate_limit_error":
+		return http.StatusTooManyRequests
+	case "overloaded_error":
+		return 529
+	case "api_error":
+		return http.StatusInternalServerError
+	default:
+		return 0
+	}
+}
+
+func anthropicErr(e *anthropicError) error {
+	if e == nil {
+		return errors.New("unknown anthropic error")
+	}
+
+	return fmt.Errorf("%s: %s", e.Type, e.Message)
+}
@@ -0,0 +1,262 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newAnthropicStandIn returns a server that behaves like the Messages API and
+// passes every request it receives to check
+func newAnthropicStandIn(t *testing.T, chunks []string, check func(anthropicRequest)) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/messages", r.URL.Path, "Unexpected endpoint")
+		assert.Equal(t, "anthropic-key", r.Header.Get("X-Api-Key"), "API key should be sent")
+		assert.Equal(t, anthropicVersion, r.Header.Get("Anthropic-Version"), "API version should be sent")
+
+		var req anthropicRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		assert.Equal(t, "claude-test", req.Model)
+		assert.Equal(t, anthropicMaxTokens, req.MaxTokens, "Default max tokens should be sent")
+
+		if check != nil {
+			check(req)
+		}
+
+		if !req.Stream {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"id":"msg_1","type":"message","role":"assistant","content":[{"type":"text","text":"Hello there."}]}`)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: message_start\ndata: {\"type\":\"message_start\"}\n\n")
+
+		for _, c := range chunks {
+			fmt.Fprintf(w, "event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":%q}}\n\n", c)
+		}
+
+		fmt.Fprint(w, "event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n")
+	}))
+}
+
+func newTestAnthropicClient(baseURL string) anthropicClient {
+	return newAnthropicClient(&AIConfig{
+		Type:    "anthropic",
+		APIKey:  "anthropic-key",
+		Model:   "claude-test",
+		BaseURL: baseURL,
+	}, AssistantConfig{Name: "Spark", Style: "butler"})
+}
+
+func TestAnthropicClient_GeneratePrompt(t *testing.T) {
+	server := newAnthropicStandIn(t, nil, func(req anthropicRequest) {
+		assert.Contains(t, req.System, "Your name is Spark.", "Persona should be sent as system prompt")
+		require.Len(t, req.Messages, 1)
+		assert.Equal(t, "user", req.Messages[0].Role)
+		assert.Equal(t, "Show the agenda", req.Messages[0].Content, "Preamble should not be repeated in the user message")
+	})
+	defer server.Close()
+
+	client := newTestAnthropicClient(server.URL)
+
+	p := func(a AssistantConfig, _ any) ([]string, error) {
+		return append(a.PromptPreamble(), "Show the agenda"), nil
+	}
+
+	result, err := client.GeneratePrompt(context.Background(), p, nil)
+
+	require.NoError(t, err, "Did not expect an error")
+	assert.Equal(t, "Hello there.", result)
+}
+
+func TestAnthropicClient_StreamPrompt(t *testing.T) {
+	chunks := []string{"Good ", "morning, ", "sir."}
+
+	server := newAnthropicStandIn(t, chunks, func(req anthropicRequest) {
+		assert.True(t, req.Stream, "Streaming should be requested")
+		assert.Empty(t, req.System, "Prompt without preamble has no system prompt")
+	})
+	defer server.Close()
+
+	client := newTestAnthropicClient(server.URL)
+
+	var received []string
+
+	result, err := client.StreamPrompt(context.Background(), testPrompt, nil, func(chunk string) error {
+		received = append(received, chunk)
+		return nil
+	})
+
+	require.NoError(t, err, "Did not expect an error")
+	assert.Equal(t, chunks, received, "Chunks should be passed on in order")
+	assert.Equal(t, "Good morning, sir.", result, "Result should contain all chunks")
+}
+
+func TestAnthropicClient_StreamChat(t *testing.T) {
+	server := newAnthropicStandIn(t, []string{"Rain, ", "sir."}, func(req anthropicRequest) {
+		assert.Contains(t, req.System, "Your name is Spark.", "Persona should be sent as system prompt")
+		assert.Contains(t, req.System, "Context:", "Context should be sent as system prompt")
+
+		roles := make([]string, 0, len(req.Messages))
+		for _, m := range req.Messages {
+			roles = append(roles, m.Role)
+		}
+
+		assert.Equal(t, []string{"user", "assistant", "user"}, roles, "Unexpected message roles")
+	})
+	defer server.Close()
+
+	client := newTestAnthropicClient(server.URL)
+
+	conv, err := NewConversation(client.assistant, nil)
+	require.NoError(t, err)
+
+	conv.AddUser("What is the weather today?")
+	conv.AddAssistant("Sunny, sir.")
+	conv.AddUser("And tomorrow?")
+
+	result, err := client.StreamChat(context.Background(), conv, func(string) error { return nil })
+
+	require.NoError(t, err, "Did not expect an error")
+	assert.Equal(t, "Rain, sir.", result)
+}
+
+func TestAnthropicClient_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprint(w, `{"type":"error","error":{"type":"rate_limit_error","message":"slow down"}}`)
+	}))
+	defer server.Close()
+
+	client := newTestAnthropicClient(server.URL)
+
+	_, err := client.GeneratePrompt(context.Background(), testPrompt, nil)
+	assert.ErrorContains(t, err, "rate_limit_error: slow down")
+}
+
+func TestAnthropicClient_StreamChat_Tools(t *testing.T) {
+	requests := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Tools    []anthropicTool `json:"tools"`
+			Messages []struct {
+				Role    string          `json:"role"`
+				Content json.RawMessage `json:"content"`
+			} `json:"messages"`
+		}
+
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		requests++
+
+		require.Len(t, req.Tools, 1, "Tools should be sent")
+		assert.Equal(t, "echo", req.Tools[0].Name)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+
+		if requests == 1 {
+			fmt.Fprint(w, "data: {\"type\":\"content_block_start\",\"index\":0,\"content_block\":{\"type\":\"tool_use\",\"id\":\"tu_1\",\"name\":\"echo\",\"input\":{}}}\n\n")
+			fmt.Fprint(w, "data: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"input_json_delta\",\"partial_json\":\"{\\\"text\\\":\"}}\n\n")
+			fmt.Fprint(w, "data: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"input_json_delta\",\"partial_json\":\"\\\"dentist\\\"}\"}}\n\n")
+			fmt.Fprint(w, "data: {\"type\":\"message_stop\"}\n\n")
+
+			return
+		}
+
+		require.Len(t, req.Messages, 3)
+		assert.JSONEq(t, `[{"type":"tool_use","id":"tu_1","name":"echo","input":{"text":"dentist"}}]`, string(req.Messages[1].Content))
+		assert.JSONEq(t, `[{"type":"tool_result","tool_use_id":"tu_1","content":"{\"echo\":\"dentist\"}"}]`, string(req.Messages[2].Content))
+
+		fmt.Fprint(w, "data: {\"type\":\"content_block_start\",\"index\":0,\"content_block\":{\"type\":\"text\",\"text\":\"\"}}\n\n")
+		fmt.Fprint(w, "data: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"On Monday.\"}}\n\n")
+		fmt.Fprint(w, "data: {\"type\":\"message_stop\"}\n\n")
+	}))
+	defer server.Close()
+
+	client := newTestAnthropicClient(server.URL)
+
+	conv, err := NewConversation(client.assistant, nil)
+	require.NoError(t, err)
+
+	conv.Tools = []Tool{testTool()}
+	conv.AddUser("When is the dentist?")
+
+	result, err := client.StreamChat(context.Background(), conv, func(string) error { return nil })
+
+	require.NoError(t, err, "Did not expect an error")
+	assert.Equal(t, 2, requests, "Expected a second request with the tool result")
+	assert.Equal(t, "On Monday.", result)
+}
+
+func TestAnthropicClient_GenerateJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req anthropicRequest
+
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		require.Len(t, req.Tools, 1, "Schema should be sent as a tool")
+		assert.Equal(t, "object", req.Tools[0].InputSchema["type"])
+		assert.Equal(t, &anthropicToolChoice{Type: "tool", Name: req.Tools[0].Name}, req.ToolChoice, "Tool should be forced")
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id":"msg_1","type":"message","role":"assistant","content":[{"type":"tool_use","id":"tu_1","name":"respond","input":{"greeting":"Hello"}}]}`)
+	}))
+	defer server.Close()
+
+	client := newTestAnthropicClient(server.URL)
+
+	result, err := client.GenerateJSON(context.Background(), testPrompt, nil, SummarySchema)
+
+	require.NoError(t, err, "Did not expect an error")
+	assert.JSONEq(t, `{"greeting":"Hello"}`, result)
+}
+
+func TestAnthropicClient_Usage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"type\":\"message_start\",\"message\":{\"usage\":{\"input_tokens\":120,\"output_tokens\":1}}}\n\n")
+		fmt.Fprint(w, "data: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"Hello.\"}}\n\n")
+		fmt.Fprint(w, "data: {\"type\":\"message_delta\",\"usage\":{\"output_tokens\":15}}\n\n")
+		fmt.Fprint(w, "data: {\"type\":\"message_stop\"}\n\n")
+	}))
+	defer server.Close()
+
+	client := newTestAnthropicClient(server.URL)
+
+	var usage []Usage
+
+	ctx := WithUsageFunc(context.Background(), func(u Usage) { usage = append(usage, u) })
+
+	_, err := client.StreamPrompt(ctx, testPrompt, nil, func(string) error { return nil })
+
+	require.NoError(t, err, "Did not expect an error")
+	assert.Equal(t, []Usage{{Provider: "anthropic", Model: "claude-test", PromptTokens: 120, CompletionTokens: 15}}, usage)
+}
+
+func TestAnthropicClient_RenderPrompt(t *testing.T) {
+	client := newTestAnthropicClient("")
+
+	p := func(a AssistantConfig, _ any) ([]string, error) {
+		return append(a.PromptPreamble(), "Show the agenda"), nil
+	}
+
+	parts, err := client.RenderPrompt(p, nil)
+	require.NoError(t, err)
+
+	require.Len(t, parts, 2)
+	assert.Equal(t, "system", parts[0].Role)
+	assert.Contains(t, parts[0].Text, "Your name is Spark.", "Persona should be rendered as system prompt")
+	assert.Equal(t, Part{Role: "user", Text: "Show the agenda"}, parts[1])
+}
@@ -0,0 +1,71 @@
+package ai
+
+import (
+	"math"
+	"strings"
+)
+
+// defaultCharsPerToken is a rough average for English text and JSON
+const defaultCharsPerToken = 4.0
+
+// ollamaOutputReserve is the part of the Ollama context that is kept for the
+// response when max_tokens is not set
+const ollamaOutputReserve = 1024
+
+// charsPerToken contains the average number of characters per token for the
+// tokenizers of each provider
+var charsPerToken = map[string]float64{
+	"gemini":    4.0,
+	"openai":    4.0,
+	"anthropic": 3.5,
+	"ollama":    3.5,
+}
+
+// CharsPerTokenRatio returns the configured ratio of characters per token,
+// or the default for the provider
+func (cc *AIConfig) CharsPerTokenRatio() float64 {
+	if cc.CharsPerToken > 0 {
+		return cc.CharsPerToken
+	}
+
+	if r, ok := charsPerToken[cc.Type]; ok {
+		return r
+	}
+
+	return defaultCharsPerToken
+}
+
+// EstimateTokens estimates the number of tokens the text will use
+func (cc *AIConfig) EstimateTokens(text string) int {
+	return int(math.Ceil(float64(len(text)) / cc.CharsPerTokenRatio()))
+}
+
+// InputBudget returns the maximum number of tokens to send to the model; 0
+// means there is no budget. Ollama defaults to its context size, minus the
+// tokens reserved for the response.
+func (cc *AIConfig) InputBudget() int {
+	if cc.TokenBudget > 0 {
+		return cc.TokenBudget
+	}
+
+	if cc.Type == "ollama" && cc.NumCtx > 0 {
+		reserve := cc.MaxTokens
+		if reserve <= 0 {
+			reserve = ollamaOutputReserve
+		}
+
+		return cc.NumCtx - min(reserve, cc.NumCtx/2)
+	}
+
+	return 0
+}
+
+// PromptTokens estimates the number of tokens the prompt will use
+func (cc *AIConfig) PromptTokens(p Prompt, assistant AssistantConfig, data any) (int, error) {
+	prompt, err := p(assistant, data)
+	if err != nil {
+		return 0, err
+	}
+
+	return cc.EstimateTokens(strings.Join(prompt, "\n")), nil
+}
@@ -0,0 +1,48 @@
+package ai
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAIConfig_EstimateTokens(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   AIConfig
+		text     string
+		expected int
+	}{
+		{"Empty text", AIConfig{Type: "openai"}, "", 0},
+		{"OpenAI", AIConfig{Type: "openai"}, "12345678", 2},
+		{"Anthropic", AIConfig{Type: "anthropic"}, "1234567", 2},
+		{"Rounds up", AIConfig{Type: "gemini"}, "123456789", 3},
+		{"Unknown type uses default", AIConfig{Type: "other"}, "12345678", 2},
+		{"Configured ratio", AIConfig{Type: "openai", CharsPerToken: 2}, "12345678", 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.config.EstimateTokens(tt.text))
+		})
+	}
+}
+
+func TestAIConfig_InputBudget(t *testing.T) {
+	assert.Equal(t, 0, (&AIConfig{Type: "openai"}).InputBudget(), "No budget by default")
+	assert.Equal(t, 1000, (&AIConfig{Type: "openai", TokenBudget: 1000}).InputBudget())
+	assert.Equal(t, 8192-1024, (&AIConfig{Type: "ollama", NumCtx: 8192}).InputBudget(), "Ollama defaults to its context size minus the response")
+	assert.Equal(t, 8192-2000, (&AIConfig{Type: "ollama", NumCtx: 8192, MaxTokens: 2000}).InputBudget(), "The response reserve follows max tokens")
+	assert.Equal(t, 1000, (&AIConfig{Type: "ollama", NumCtx: 2000, MaxTokens: 4000}).InputBudget(), "At least half of the context is for the prompt")
+	assert.Equal(t, 1000, (&AIConfig{Type: "ollama", NumCtx: 8192, TokenBudget: 1000}).InputBudget())
+}
+
+func TestAIConfig_PromptTokens(t *testing.T) {
+	cc := &AIConfig{Type: "openai"}
+
+	tokens, err := cc.PromptTokens(testPrompt, AssistantConfig{}, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, tokens, `"Say hello" is 9 characters`)
+}
@@ -3,25 +3,118 @@ package ai
 import (
 	"context"
 	"fmt"
-	"log/slog"
+	"time"
+
+	"github.com/jovandeginste/spark-personal-assistant/pkg/data"
 )
 
 type AIConfig struct {
 	Type   string `mapstructure:"type"`
 	APIKey string `mapstructure:"api_key"`
 	Model  string `mapstructure:"model"`
+
+	// MaxTokens limits the length of the response; required by Anthropic
+	MaxTokens   int      `mapstructure:"max_tokens"`
+	Temperature *float64 `mapstructure:"temperature"`
+
+	// TokenBudget limits the estimated size of the prompt; entries are
+	// trimmed when it is exceeded
+	TokenBudget   int     `mapstructure:"token_budget"`
+	CharsPerToken float64 `mapstructure:"chars_per_token"`
+
+	// Host is the address of the Ollama server; defaults to OLLAMA_HOST
+	Host   string `mapstructure:"host"`
+	NumCtx int    `mapstructure:"num_ctx"`
+
+	// BaseURL points the OpenAI client to an OpenAI-compatible endpoint, or
+	// the Anthropic client to a different API host
+	BaseURL      string            `mapstructure:"base_url"`
+	Headers      map[string]string `mapstructure:"headers"`
+	Organization string            `mapstructure:"organization"`
+	Project      string            `mapstructure:"project"`
+
+	// StreamUsage requests the token usage in streamed responses; turn it off
+	// for OpenAI-compatible servers that reject stream_options
+	StreamUsage *bool `mapstructure:"stream_usage"`
+
+	// Retries, Backoff and Timeout apply to every attempt at this provider
+	// before Spark fails over to the next one
+	Retries int           `mapstructure:"retries"`
+	Backoff time.Duration `mapstructure:"backoff"`
+	Timeout time.Duration `mapstructure:"timeout"`
+
+	// Recordings is the directory with the responses of the replay and fake
+	// providers. When Record is set, the replay provider passes prompts
+	// without a recording on to that provider and records its responses.
+	Recordings string    `mapstructure:"recordings"`
+	Record     *AIConfig `mapstructure:"record"`
+
+	// Response is what the fake provider answers without a recording
+	Response string `mapstructure:"response"`
+
+	// EmbeddingModel computes the vectors of entries for retrieval; every
+	// provider except Anthropic has a default
+	EmbeddingModel string `mapstructure:"embedding_model"`
 }
 
 type AssistantConfig struct {
 	Name      string `mapstructure:"name"`
 	Style     string `mapstructure:"style"`
 	StyleFile string `mapstructure:"style_file"`
+
+	// The persona's preferences, from the frontmatter of its file. They
+	// override the locale's language and the settings of the LLMs.
+	Language    string   `mapstructure:"language" yaml:"language"`
+	Temperature *float64 `mapstructure:"temperature" yaml:"temperature"`
+	MaxTokens   int      `mapstructure:"max_tokens" yaml:"max_tokens"`
+	Provider    string   `mapstructure:"provider" yaml:"provider"`
+	Model       string   `mapstructure:"model" yaml:"model"`
+
+	// Greeting tells the persona how to greet its employers
+	Greeting string `mapstructure:"greeting" yaml:"greeting"`
+
+	// Locale is the language and notations to use, from the config
+	Locale data.Locale `mapstructure:"-" yaml:"-"`
+	// Clock tells the day of the prompts; the wall clock if not set
+	Clock data.Clock `mapstructure:"-" yaml:"-"`
+}
+
+// Now returns the current time of the clock of the assistant
+func (a AssistantConfig) Now() time.Time {
+	if a.Clock == nil {
+		return time.Now()
+	}
+
+	return a.Clock.Now()
+}
+
+// StreamFunc is called with every chunk of text as it is generated
+type StreamFunc func(chunk string) error
+
+// Part is a part of a request, with the role it is sent as
+type Part struct {
+	Role string
+	Text string
 }
 
 type Client interface {
 	APIKey() string
 	Model() string
 	GeneratePrompt(context.Context, Prompt, any) (string, error)
+	StreamPrompt(context.Context, Prompt, any, StreamFunc) (string, error)
+	StreamChat(context.Context, *Conversation, StreamFunc) (string, error)
+
+	// GenerateJSON asks for a response that matches the schema
+	GenerateJSON(context.Context, Prompt, any, *Schema) (string, error)
+
+	// RenderPrompt returns the parts of the prompt as they are sent
+	RenderPrompt(Prompt, any) ([]Part, error)
+
+	// EmbeddingModel is the model used by Embed, or empty if the provider
+	// has no embeddings
+	EmbeddingModel() string
+	// Embed returns a vector for each text
+	Embed(context.Context, []string) ([][]float32, error)
 }
 
 func NewClient(cc *AIConfig, ac AssistantConfig) (Client, error) {
@@ -29,12 +122,20 @@ func NewClient(cc *AIConfig, ac AssistantConfig) (Client, error) {
 
 	switch cc.Type {
 	case "gemini":
-		c = geminiClient{apiKey: cc.APIKey, model: cc.Model, assistant: ac}
+		c = newGeminiClient(cc, ac)
 	case "openai":
-		c = openaiClient{apiKey: cc.APIKey, model: cc.Model, assistant: ac}
+		c = newOpenAIClient(cc, ac)
+	case "anthropic":
+		c = newAnthropicClient(cc, ac)
 	case "ollama":
-		slog.Info("ollama does not work yet - input size is too large?")
-		c = ollamaClient{model: cc.Model, assistant: ac}
+		c = newOllamaClient(cc, ac)
+	case "replay", "fake":
+		r, err := newReplayClient(cc, ac)
+		if err != nil {
+			return nil, err
+		}
+
+		c = r
 	default:
 		return nil, fmt.Errorf("unknown type: %s", cc.Type)
 	}
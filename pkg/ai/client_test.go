@@ -42,6 +42,17 @@ func TestNewClient(t *testing.T) {
 			expectError:        false,
 			expectedClientType: openaiClient{}, // Expected concrete type
 		},
+		{
+			name: "Create Anthropic client",
+			aiConfig: &AIConfig{
+				Type:   "anthropic",
+				APIKey: "anthropic-key",
+				Model:  "claude-sonnet-4-0",
+			},
+			assistantConfig:    assistantConfig,
+			expectError:        false,
+			expectedClientType: anthropicClient{}, // Expected concrete type
+		},
 		{
 			name: "Create Ollama client",
 			aiConfig: &AIConfig{
@@ -52,6 +63,15 @@ func TestNewClient(t *testing.T) {
 			expectError:        false,
 			expectedClientType: ollamaClient{}, // Expected concrete type
 		},
+		{
+			name: "Create fake client",
+			aiConfig: &AIConfig{
+				Type: "fake",
+			},
+			assistantConfig:    assistantConfig,
+			expectError:        false,
+			expectedClientType: replayClient{}, // Expected concrete type
+		},
 		{
 			name: "Unknown AI type",
 			aiConfig: &AIConfig{
@@ -0,0 +1,88 @@
+package ai
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+type Role string
+
+const (
+	RoleUser      Role = "user"
+	RoleAssistant Role = "assistant"
+)
+
+type Message struct {
+	Role    Role
+	Content string
+}
+
+// Conversation is a multi-turn chat: the persona's system instruction, the
+// context (entries, user data, ...) and the alternating user and assistant
+// turns. The model may call the tools to look up more information.
+type Conversation struct {
+	System   []string
+	Context  string
+	Messages []Message
+	Tools    []Tool
+}
+
+func NewConversation(assistant AssistantConfig, data any) (*Conversation, error) {
+	c := &Conversation{
+		System: append(assistant.PromptPreamble(),
+			"Provide an answer to your employers' questions.",
+			"Take the information in the context into account to answer the questions.",
+		),
+	}
+
+	if err := c.SetContext(data); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// SetContext replaces the context, eg. with the entries that are relevant to
+// the next question
+func (c *Conversation) SetContext(data any) error {
+	j, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	c.Context = "Context:\n" + string(j)
+
+	return nil
+}
+
+func (c *Conversation) AddUser(content string) {
+	c.Messages = append(c.Messages, Message{Role: RoleUser, Content: content})
+}
+
+func (c *Conversation) AddAssistant(content string) {
+	c.Messages = append(c.Messages, Message{Role: RoleAssistant, Content: content})
+}
+
+// Text returns the conversation as it is sent to the model: the system
+// instruction, the context, the available tools and all turns
+func (c *Conversation) Text() string {
+	var b strings.Builder
+
+	b.WriteString(strings.Join(c.System, "\n"))
+	b.WriteString("\n" + c.Context + "\n")
+
+	if len(c.Tools) > 0 {
+		names := make([]string, 0, len(c.Tools))
+		for _, t := range c.Tools {
+			names = append(names, t.Name)
+		}
+
+		b.WriteString("Tools: " + strings.Join(names, ", ") + "\n")
+	}
+
+	for _, m := range c.Messages {
+		b.WriteString(string(m.Role) + ": " + m.Content + "\n")
+	}
+
+	return b.String()
+}
@@ -0,0 +1,43 @@
+package ai
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewConversation(t *testing.T) {
+	conv, err := NewConversation(AssistantConfig{Name: "Spark", Style: "butler"}, map[string]string{"key": "value"})
+	require.NoError(t, err)
+
+	assert.Contains(t, conv.System, "Your name is Spark.", "System instruction should contain the persona")
+	assert.Equal(t, "Context:\n{\"key\":\"value\"}", conv.Context, "Context should contain the data as JSON")
+	assert.Empty(t, conv.Messages, "New conversation should have no messages")
+
+	conv.AddUser("Hello")
+	conv.AddAssistant("Good day")
+
+	assert.Equal(t, []Message{
+		{Role: RoleUser, Content: "Hello"},
+		{Role: RoleAssistant, Content: "Good day"},
+	}, conv.Messages)
+}
+
+func TestNewConversation_InvalidData(t *testing.T) {
+	_, err := NewConversation(AssistantConfig{}, make(chan int))
+	assert.Error(t, err, "Unmarshallable data should return an error")
+}
+
+func TestConversation_Text(t *testing.T) {
+	conv := &Conversation{
+		System:  []string{"Your name is Spark.", "Be brief."},
+		Context: "Context:\n{}",
+		Tools:   []Tool{testTool()},
+	}
+
+	conv.AddUser("Hello")
+	conv.AddAssistant("Good day")
+
+	assert.Equal(t, "Your name is Spark.\nBe brief.\nContext:\n{}\nTools: echo\nuser: Hello\nassistant: Good day\n", conv.Text())
+}
@@ -0,0 +1,66 @@
+package ai
+
+import (
+	"errors"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// ErrNoEmbeddings is returned by providers without an embeddings endpoint
+var ErrNoEmbeddings = errors.New("provider has no embeddings")
+
+// defaultEmbeddingModels are used when no embedding model is configured
+var defaultEmbeddingModels = map[string]string{
+	"gemini": "text-embedding-004",
+	"openai": "text-embedding-3-small",
+	"ollama": "nomic-embed-text",
+	"replay": fakeEmbeddingModel,
+	"fake":   fakeEmbeddingModel,
+}
+
+const (
+	fakeEmbeddingModel      = "fake-embedding"
+	fakeEmbeddingDimensions = 256
+)
+
+func embeddingModel(cc *AIConfig) string {
+	if cc.EmbeddingModel != "" {
+		return cc.EmbeddingModel
+	}
+
+	return defaultEmbeddingModels[cc.Type]
+}
+
+// fakeEmbedding hashes the words of the text into a normalized vector, so
+// texts with the same words are similar
+func fakeEmbedding(text string) []float32 {
+	v := make([]float32, fakeEmbeddingDimensions)
+
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	for _, w := range words {
+		h := fnv.New32a()
+		h.Write([]byte(w))
+		v[h.Sum32()%fakeEmbeddingDimensions]++
+	}
+
+	var norm float64
+	for _, x := range v {
+		norm += float64(x * x)
+	}
+
+	if norm == 0 {
+		return v
+	}
+
+	norm = math.Sqrt(norm)
+	for i := range v {
+		v[i] = float32(float64(v[i]) / norm)
+	}
+
+	return v
+}
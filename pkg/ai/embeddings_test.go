@@ -0,0 +1,97 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jovandeginste/spark-personal-assistant/pkg/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeEmbedding(t *testing.T) {
+	client, err := NewClient(&AIConfig{Type: "fake"}, AssistantConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, fakeEmbeddingModel, client.EmbeddingModel())
+
+	vectors, err := client.Embed(context.Background(), []string{
+		"What did we plan for the school trip?",
+		"Plan the school trip to the zoo",
+		"Dentist appointment",
+	})
+	require.NoError(t, err)
+	require.Len(t, vectors, 3)
+
+	question := data.Vector(vectors[0])
+
+	assert.Greater(t, question.Similarity(vectors[1]), question.Similarity(vectors[2]))
+	assert.Equal(t, fakeEmbedding("Dentist appointment"), vectors[2], "Embeddings should be deterministic")
+}
+
+func TestOpenAIClient_Embed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/embeddings", r.URL.Path, "Unexpected endpoint")
+
+		var req struct {
+			Model string   `json:"model"`
+			Input []string `json:"input"`
+		}
+
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "text-embedding-3-small", req.Model, "Default embedding model should be used")
+		assert.Equal(t, []string{"one", "two"}, req.Input)
+
+		w.Header().Set("Content-Type", "application/json")
+		// Out of order, to check that vectors are matched by index
+		fmt.Fprint(w, `{"object":"list","model":"text-embedding-3-small","data":[`+
+			`{"object":"embedding","index":1,"embedding":[0,1]},`+
+			`{"object":"embedding","index":0,"embedding":[1,0]}],`+
+			`"usage":{"prompt_tokens":2,"total_tokens":2}}`)
+	}))
+	defer server.Close()
+
+	client := newTestOpenAIClient(server.URL)
+
+	vectors, err := client.Embed(context.Background(), []string{"one", "two"})
+	require.NoError(t, err)
+	assert.Equal(t, [][]float32{{1, 0}, {0, 1}}, vectors)
+}
+
+func TestOpenAIClient_Embed_InvalidIndex(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"object":"list","model":"text-embedding-3-small","data":[`+
+			`{"object":"embedding","index":3,"embedding":[0,1]}],`+
+			`"usage":{"prompt_tokens":1,"total_tokens":1}}`)
+	}))
+	defer server.Close()
+
+	client := newTestOpenAIClient(server.URL)
+
+	_, err := client.Embed(context.Background(), []string{"one"})
+	assert.ErrorContains(t, err, "invalid embedding index 3")
+}
+
+func TestChain_Embed(t *testing.T) {
+	chain, err := NewChain([]*AIConfig{
+		{Type: "anthropic", Model: "claude"},
+		{Type: "fake"},
+	}, AssistantConfig{}, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, fakeEmbeddingModel, chain.EmbeddingModel(), "Providers without embeddings should be skipped")
+
+	vectors, err := chain.Embed(context.Background(), []string{"hello"})
+	require.NoError(t, err)
+	assert.Len(t, vectors, 1)
+
+	chain, err = NewChain([]*AIConfig{{Type: "anthropic", Model: "claude"}}, AssistantConfig{}, nil)
+	require.NoError(t, err)
+
+	_, err = chain.Embed(context.Background(), []string{"hello"})
+	assert.ErrorIs(t, err, ErrNoEmbeddings)
+}
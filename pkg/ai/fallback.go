@@ -0,0 +1,268 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/ollama/ollama/api"
+	"github.com/openai/openai-go"
+	"google.golang.org/genai"
+)
+
+// defaultBackoff is the delay before the first retry when no backoff is
+// configured; it doubles with every retry
+const defaultBackoff = time.Second
+
+type chainLink struct {
+	config *AIConfig
+	client Client
+}
+
+// Chain is a Client that tries each provider in order. Rate limits, server
+// errors and timeouts are retried and then fail over to the next provider;
+// other errors are returned immediately.
+type Chain struct {
+	links     []chainLink
+	used      *AIConfig
+	assistant AssistantConfig
+	logger    *slog.Logger
+}
+
+func NewChain(configs []*AIConfig, ac AssistantConfig, logger *slog.Logger) (*Chain, error) {
+	if len(configs) == 0 {
+		return nil, errors.New("no LLM configured")
+	}
+
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	c := &Chain{assistant: ac, logger: logger}
+
+	for _, cc := range configs {
+		client, err := NewClient(cc, ac)
+		if err != nil {
+			return nil, err
+		}
+
+		// The chain retries and fails over itself, so the SDK should not
+		if oc, ok := client.(openaiClient); ok {
+			oc.chained = true
+			client = oc
+		}
+
+		c.links = append(c.links, chainLink{config: cc, client: client})
+	}
+
+	return c, nil
+}
+
+// Assistant returns the persona, and the locale, the prompts are rendered for
+func (c *Chain) Assistant() AssistantConfig {
+	return c.assistant
+}
+
+// Primary returns the config of the first provider, which handles every call
+// unless it fails
+func (c *Chain) Primary() *AIConfig {
+	return c.links[0].config
+}
+
+// Used returns the config of the provider that produced the last response
+func (c *Chain) Used() *AIConfig {
+	if c.used == nil {
+		return c.Primary()
+	}
+
+	return c.used
+}
+
+func (c *Chain) APIKey() string {
+	return c.Used().APIKey
+}
+
+func (c *Chain) Model() string {
+	return c.Used().Model
+}
+
+func (c *Chain) GeneratePrompt(ctx context.Context, p Prompt, data any) (string, error) {
+	return c.run(ctx, nil, func(ctx context.Context, client Client, _ StreamFunc) (string, error) {
+		return client.GeneratePrompt(ctx, p, data)
+	})
+}
+
+func (c *Chain) StreamPrompt(ctx context.Context, p Prompt, data any, fn StreamFunc) (string, error) {
+	return c.run(ctx, fn, func(ctx context.Context, client Client, fn StreamFunc) (string, error) {
+		return client.StreamPrompt(ctx, p, data, fn)
+	})
+}
+
+func (c *Chain) StreamChat(ctx context.Context, conv *Conversation, fn StreamFunc) (string, error) {
+	return c.run(ctx, fn, func(ctx context.Context, client Client, fn StreamFunc) (string, error) {
+		return client.StreamChat(ctx, conv, fn)
+	})
+}
+
+func (c *Chain) GenerateJSON(ctx context.Context, p Prompt, data any, schema *Schema) (string, error) {
+	return c.run(ctx, nil, func(ctx context.Context, client Client, _ StreamFunc) (string, error) {
+		return client.GenerateJSON(ctx, p, data, schema)
+	})
+}
+
+// RenderPrompt renders the prompt for the first provider
+func (c *Chain) RenderPrompt(p Prompt, data any) ([]Part, error) {
+	return c.links[0].client.RenderPrompt(p, data)
+}
+
+// embedder returns the first provider with embeddings. Embeddings do not fail
+// over, since vectors of different models can not be compared.
+func (c *Chain) embedder() (chainLink, bool) {
+	for _, l := range c.links {
+		if l.client.EmbeddingModel() != "" {
+			return l, true
+		}
+	}
+
+	return chainLink{}, false
+}
+
+func (c *Chain) EmbeddingModel() string {
+	l, ok := c.embedder()
+	if !ok {
+		return ""
+	}
+
+	return l.client.EmbeddingModel()
+}
+
+func (c *Chain) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	l, ok := c.embedder()
+	if !ok {
+		return nil, ErrNoEmbeddings
+	}
+
+	if l.config.Timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, l.config.Timeout)
+		defer cancel()
+	}
+
+	return l.client.Embed(ctx, texts)
+}
+
+// run calls generate for each provider until one succeeds. Once a chunk has
+// been streamed or a tool has run, the error is returned instead, since the
+// output and the effects of the tool can not be taken back.
+func (c *Chain) run(ctx context.Context, fn StreamFunc, generate func(context.Context, Client, StreamFunc) (string, error)) (string, error) {
+	var (
+		streamed   bool
+		toolCalled bool
+		err        error
+	)
+
+	ctx = withToolCalls(ctx, &toolCalled)
+
+	stream := func(chunk string) error {
+		streamed = true
+
+		if fn == nil {
+			return nil
+		}
+
+		return fn(chunk)
+	}
+
+	for i, l := range c.links {
+		if i > 0 {
+			c.logger.Warn("Failing over to the next LLM provider", "type", l.config.Type, "model", l.config.Model, "error", err)
+		}
+
+		backoff := l.config.Backoff
+		if backoff <= 0 {
+			backoff = defaultBackoff
+		}
+
+		for attempt := 0; attempt <= l.config.Retries; attempt++ {
+			if attempt > 0 {
+				c.logger.Warn("Retrying LLM provider", "type", l.config.Type, "model", l.config.Model, "attempt", attempt, "error", err)
+
+				select {
+				case <-ctx.Done():
+					return "", ctx.Err()
+				case <-time.After(backoff):
+				}
+
+				backoff *= 2
+			}
+
+			var result string
+
+			result, err = c.attempt(ctx, l, stream, generate)
+			if err == nil {
+				c.used = l.config
+				return result, nil
+			}
+
+			if streamed || toolCalled || ctx.Err() != nil || !retryable(err) {
+				return "", err
+			}
+		}
+	}
+
+	return "", err
+}
+
+func (c *Chain) attempt(ctx context.Context, l chainLink, fn StreamFunc, generate func(context.Context, Client, StreamFunc) (string, error)) (string, error) {
+	if l.config.Timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, l.config.Timeout)
+		defer cancel()
+	}
+
+	return generate(ctx, l.client, fn)
+}
+
+// retryable reports whether the error is a rate limit, a server error, a
+// timeout or an unreachable provider
+func retryable(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+
+	var (
+		geminiErr       genai.APIError
+		openaiErr       *openai.Error
+		ollamaErr       api.StatusError
+		anthropicStatus anthropicStatusError
+		status          int
+	)
+
+	switch {
+	case errors.As(err, &geminiErr):
+		status = geminiErr.Code
+	case errors.As(err, &openaiErr):
+		status = openaiErr.StatusCode
+	case errors.As(err, &ollamaErr):
+		status = ollamaErr.StatusCode
+	case errors.As(err, &anthropicStatus):
+		status = anthropicStatus.StatusCode
+	}
+
+	return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
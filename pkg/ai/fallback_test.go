@@ -0,0 +1,201 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFailingServer returns a server that always answers with the status and
+// counts the requests it receives
+func newFailingServer(t *testing.T, status int, requests *int) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		*requests++
+
+		w.WriteHeader(status)
+		fmt.Fprint(w, `{"type":"error","error":{"type":"api_error","message":"failed"}}`)
+	}))
+}
+
+func newTestChainConfig(baseURL string) *AIConfig {
+	return &AIConfig{
+		Type:    "anthropic",
+		APIKey:  "anthropic-key",
+		Model:   "claude-test",
+		BaseURL: baseURL,
+		Retries: 1,
+		Backoff: time.Millisecond,
+	}
+}
+
+func TestChain_Failover(t *testing.T) {
+	var failed int
+
+	primary := newFailingServer(t, http.StatusServiceUnavailable, &failed)
+	defer primary.Close()
+
+	fallback := newAnthropicStandIn(t, []string{"Good ", "morning."}, nil)
+	defer fallback.Close()
+
+	configs := []*AIConfig{newTestChainConfig(primary.URL), newTestChainConfig(fallback.URL)}
+
+	chain, err := NewChain(configs, AssistantConfig{Name: "Spark"}, nil)
+	require.NoError(t, err)
+
+	result, err := chain.StreamPrompt(context.Background(), testPrompt, nil, func(string) error { return nil })
+
+	require.NoError(t, err, "Did not expect an error")
+	assert.Equal(t, "Good morning.", result)
+	assert.Equal(t, 2, failed, "Primary provider should be retried once")
+	assert.Same(t, configs[1], chain.Used(), "Fallback provider should have produced the response")
+}
+
+func TestChain_NotRetryable(t *testing.T) {
+	var failed int
+
+	primary := newFailingServer(t, http.StatusBadRequest, &failed)
+	defer primary.Close()
+
+	fallback := newAnthropicStandIn(t, nil, func(anthropicRequest) {
+		t.Error("Fallback provider should not be called")
+	})
+	defer fallback.Close()
+
+	chain, err := NewChain([]*AIConfig{newTestChainConfig(primary.URL), newTestChainConfig(fallback.URL)}, AssistantConfig{}, nil)
+	require.NoError(t, err)
+
+	_, err = chain.GeneratePrompt(context.Background(), testPrompt, nil)
+
+	assert.ErrorContains(t, err, "400 Bad Request")
+	assert.Equal(t, 1, failed, "Bad requests should not be retried")
+}
+
+func TestChain_Timeout(t *testing.T) {
+	done := make(chan struct{})
+
+	slow := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+		<-done
+	}))
+	defer slow.Close()
+	defer close(done)
+
+	fallback := newAnthropicStandIn(t, nil, nil)
+	defer fallback.Close()
+
+	primary := newTestChainConfig(slow.URL)
+	primary.Retries = 0
+	primary.Timeout = 10 * time.Millisecond
+
+	chain, err := NewChain([]*AIConfig{primary, newTestChainConfig(fallback.URL)}, AssistantConfig{}, nil)
+	require.NoError(t, err)
+
+	result, err := chain.GeneratePrompt(context.Background(), testPrompt, nil)
+
+	require.NoError(t, err, "Did not expect an error")
+	assert.Equal(t, "Hello there.", result)
+}
+
+func TestChain_ToolCalled(t *testing.T) {
+	requests := 0
+
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+
+		if requests > 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprint(w, `{"type":"error","error":{"type":"api_error","message":"failed"}}`)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"type\":\"content_block_start\",\"index\":0,\"content_block\":{\"type\":\"tool_use\",\"id\":\"tu_1\",\"name\":\"echo\",\"input\":{\"text\":\"dentist\"}}}\n\n")
+		fmt.Fprint(w, "data: {\"type\":\"message_stop\"}\n\n")
+	}))
+	defer primary.Close()
+
+	fallback := newAnthropicStandIn(t, []string{"On Monday."}, func(anthropicRequest) {
+		t.Error("Fallback provider should not be called")
+	})
+	defer fallback.Close()
+
+	chain, err := NewChain([]*AIConfig{newTestChainConfig(primary.URL), newTestChainConfig(fallback.URL)}, AssistantConfig{}, nil)
+	require.NoError(t, err)
+
+	calls := 0
+	tool := testTool()
+	echo := tool.Call
+	tool.Call = func(ctx context.Context, args ToolArgs) (any, error) {
+		calls++
+		return echo(ctx, args)
+	}
+
+	conv, err := NewConversation(AssistantConfig{Name: "Spark"}, nil)
+	require.NoError(t, err)
+
+	conv.Tools = []Tool{tool}
+	conv.AddUser("When is the dentist?")
+
+	_, err = chain.StreamChat(context.Background(), conv, func(string) error { return nil })
+
+	assert.ErrorContains(t, err, "503")
+	assert.Equal(t, 2, requests, "The conversation should not be retried after a tool ran")
+	assert.Equal(t, 1, calls, "The tool should run once")
+}
+
+func TestChain_OpenAIRetries(t *testing.T) {
+	var failed int
+
+	primary := newFailingServer(t, http.StatusServiceUnavailable, &failed)
+	defer primary.Close()
+
+	fallback := newAnthropicStandIn(t, nil, nil)
+	defer fallback.Close()
+
+	cc := &AIConfig{Type: "openai", APIKey: "openai-key", Model: "gpt-test", BaseURL: primary.URL, Retries: 1, Backoff: time.Millisecond}
+
+	chain, err := NewChain([]*AIConfig{cc, newTestChainConfig(fallback.URL)}, AssistantConfig{}, nil)
+	require.NoError(t, err)
+
+	_, err = chain.GeneratePrompt(context.Background(), testPrompt, nil)
+
+	require.NoError(t, err, "Did not expect an error")
+	assert.Equal(t, 2, failed, "Only the chain should retry, not the SDK")
+}
+
+func TestNewChain(t *testing.T) {
+	_, err := NewChain(nil, AssistantConfig{}, nil)
+	assert.Error(t, err, "Expected an error without providers")
+
+	_, err = NewChain([]*AIConfig{{Type: "unknown"}}, AssistantConfig{}, nil)
+	assert.ErrorContains(t, err, "unknown type: unknown")
+}
+
+func Test_retryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"rate limit", anthropicStatusError{StatusCode: http.StatusTooManyRequests}, true},
+		{"server error", fmt.Errorf("wrapped: %w", anthropicStatusError{StatusCode: http.StatusBadGateway}), true},
+		{"bad request", anthropicStatusError{StatusCode: http.StatusBadRequest}, false},
+		{"timeout", context.DeadlineExceeded, true},
+		{"other", errors.New("something else"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, retryable(tt.err))
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Format is a summary format. The instructions to the model are a
+// text/template, rendered with FormatData.
+type Format struct {
+	Description  string `mapstructure:"description"`
+	Instructions string `mapstructure:"instructions"`
+	File         string `mapstructure:"file"`
+	Overdue      bool   `mapstructure:"overdue"` // include overdue tasks from before the period
+}
+
+// FormatData is available in the instructions template of a format
+type FormatData struct {
+	Date      time.Time
+	Assistant AssistantConfig
+	Data      any
+}
+
+// Formats are user-defined formats by name; they take precedence over the
+// built-in formats
+type Formats map[string]Format
+
+var builtinFormats = Formats{
+	"custom": {
+		Description: "Answer the custom prompt",
+		Instructions: "Provide an answer to your employers' question.\n" +
+			"Take the following information into account to answer the question.",
+	},
+	"today": {
+		Description: "Today's summary and a quick look at tomorrow",
+		Instructions: "Start your response with a suitable greeting and comment about today's weather forecast if you have this information. " +
+			"Only include today's and tomorrow's entries. Be verbose.\n" +
+			"Remind your employers of their overdue tasks.",
+		Overdue: true,
+	},
+	"week": {
+		Description: "This week's schedule, todo's and reminders",
+		Instructions: "Only include this week's entries.\n" +
+			"Compile a schedule and a summarized overview of todo's, and reminders.\n" +
+			"Include the overdue tasks in the todo's.",
+		Overdue: true,
+	},
+	"full": {
+		Description: "All entries in scope",
+		Instructions: "Add a quick summary of the past week's important entries. Be verbose about today's entries. " +
+			"Add a quick summary of future important entries - one line per day. Add weather information for days with outside entries.",
+	},
+}
+
+// Load reads the instructions from the template file, if the format has one
+func (f *Format) Load() error {
+	if f.File == "" {
+		return nil
+	}
+
+	b, err := os.ReadFile(f.File)
+	if err != nil {
+		return err
+	}
+
+	f.Instructions = string(b)
+
+	return nil
+}
+
+// Get returns the user-defined or built-in format by name
+func (fs Formats) Get(name string) (Format, bool) {
+	if f, ok := fs[name]; ok {
+		return f, true
+	}
+
+	f, ok := builtinFormats[name]
+
+	return f, ok
+}
+
+// Names returns the names of all user-defined and built-in formats, sorted
+func (fs Formats) Names() []string {
+	names := make([]string, 0, len(fs)+len(builtinFormats))
+
+	for n := range builtinFormats {
+		names = append(names, n)
+	}
+
+	for n := range fs {
+		if _, ok := builtinFormats[n]; !ok {
+			names = append(names, n)
+		}
+	}
+
+	slices.Sort(names)
+
+	return names
+}
+
+// Overdue returns whether the format includes the overdue tasks
+func (fs Formats) Overdue(name string) bool {
+	f, ok := fs.Get(name)
+
+	return ok && f.Overdue
+}
+
+// PromptFor returns the prompt for a user-defined or built-in format
+func (fs Formats) PromptFor(name string) (Prompt, error) {
+	f, ok := fs.Get(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown format: %s", name)
+	}
+
+	if _, err := f.template(); err != nil {
+		return nil, fmt.Errorf("format %s: %w", name, err)
+	}
+
+	return f.render, nil
+}
+
+func (f Format) template() (*template.Template, error) {
+	return template.New("instructions").Option("missingkey=error").Parse(f.Instructions)
+}
+
+// render returns the preamble, the rendered instructions and the information
+// as JSON
+func (f Format) render(assistant AssistantConfig, info any) ([]string, error) {
+	t, err := f.template()
+	if err != nil {
+		return nil, err
+	}
+
+	var instructions strings.Builder
+
+	if err := t.Execute(&instructions, FormatData{
+		Date:      assistant.Now(),
+		Assistant: assistant,
+		Data:      info,
+	}); err != nil {
+		return nil, err
+	}
+
+	j, err := json.Marshal(info)
+	if err != nil {
+		return nil, err
+	}
+
+	c := append(assistant.PromptPreamble(),
+		strings.Split(strings.TrimSpace(instructions.String()), "\n")...,
+	)
+
+	return append(c, "Information:", string(j)), nil
+}
@@ -0,0 +1,113 @@
+package ai
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jovandeginste/spark-personal-assistant/pkg/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormats_PromptFor(t *testing.T) {
+	formats := Formats{
+		"weekend": {Instructions: "Only include the weekend's entries for {{ index .Data.Names 0 }}.\nGreet as {{ .Assistant.Name }}."},
+		"today":   {Instructions: "Overridden"},
+		"broken":  {Instructions: "{{ .Unclosed"},
+	}
+
+	assistant := AssistantConfig{Name: "Spark"}
+	data := struct{ Names []string }{Names: []string{"Jane"}}
+
+	t.Run("User-defined format", func(t *testing.T) {
+		p, err := formats.PromptFor("weekend")
+		require.NoError(t, err)
+
+		prompt, err := p(assistant, data)
+		require.NoError(t, err)
+
+		preamble := assistant.PromptPreamble()
+		assert.Equal(t, preamble, prompt[:len(preamble)], "Prompt should start with the preamble")
+		assert.Equal(t, []string{
+			"Only include the weekend's entries for Jane.",
+			"Greet as Spark.",
+			"Information:",
+			`{"Names":["Jane"]}`,
+		}, prompt[len(preamble):])
+	})
+
+	t.Run("User-defined format overrides built-in", func(t *testing.T) {
+		p, err := formats.PromptFor("today")
+		require.NoError(t, err)
+
+		prompt, err := p(assistant, nil)
+		require.NoError(t, err)
+		assert.Contains(t, prompt, "Overridden")
+	})
+
+	t.Run("Built-in format", func(t *testing.T) {
+		p, err := formats.PromptFor("week")
+		require.NoError(t, err)
+
+		prompt, err := p(assistant, nil)
+		require.NoError(t, err)
+		assert.Contains(t, prompt, "Only include this week's entries.")
+	})
+
+	t.Run("Overdue tasks", func(t *testing.T) {
+		assert.True(t, Formats(nil).Overdue("week"))
+		assert.False(t, Formats(nil).Overdue("full"))
+		assert.False(t, formats.Overdue("today"), "An overridden format should decide for itself")
+		assert.False(t, formats.Overdue("nope"))
+	})
+
+	t.Run("Invalid template", func(t *testing.T) {
+		_, err := formats.PromptFor("broken")
+		assert.ErrorContains(t, err, "format broken")
+	})
+
+	t.Run("Unknown format", func(t *testing.T) {
+		_, err := formats.PromptFor("monthly")
+		assert.ErrorContains(t, err, "unknown format: monthly")
+	})
+}
+
+func TestFormats_Names(t *testing.T) {
+	formats := Formats{"weekend": {}, "today": {}}
+
+	assert.Equal(t, []string{"custom", "full", "today", "week", "weekend"}, formats.Names())
+}
+
+func TestFormat_Load(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "monthly.tmpl")
+	require.NoError(t, os.WriteFile(file, []byte("Review the month"), 0o600))
+
+	f := Format{File: file}
+	require.NoError(t, f.Load())
+	assert.Equal(t, "Review the month", f.Instructions)
+
+	f = Format{File: filepath.Join(t.TempDir(), "missing.tmpl")}
+	assert.Error(t, f.Load())
+}
+
+func TestPromptFor(t *testing.T) {
+	for _, name := range []string{"custom", "today", "week", "full"} {
+		p, err := PromptFor(name)
+		require.NoError(t, err, name)
+		assert.NotNil(t, p, name)
+	}
+}
+
+func TestFormats_Clock(t *testing.T) {
+	clock := data.FixedClock(time.Date(2031, time.July, 14, 8, 0, 0, 0, data.LocalTimezone))
+
+	p, err := Formats{"dated": {Instructions: `Summarize {{ .Date.Format "2006-01-02" }}.`}}.PromptFor("dated")
+	require.NoError(t, err)
+
+	prompt, err := p(AssistantConfig{Name: "Spark", Clock: clock}, nil)
+	require.NoError(t, err)
+	assert.Contains(t, prompt, "Today is: Monday, 2031-07-14")
+	assert.Contains(t, prompt, "Summarize 2031-07-14.")
+}
@@ -2,14 +2,30 @@ package ai
 
 import (
 	"context"
+	"encoding/json"
+	"strings"
 
 	"google.golang.org/genai"
 )
 
 type geminiClient struct {
-	apiKey    string
-	model     string
-	assistant AssistantConfig
+	apiKey         string
+	model          string
+	embeddingModel string
+	maxTokens      int
+	temperature    *float64
+	assistant      AssistantConfig
+}
+
+func newGeminiClient(cc *AIConfig, ac AssistantConfig) geminiClient {
+	return geminiClient{
+		apiKey:         cc.APIKey,
+		model:          cc.Model,
+		embeddingModel: embeddingModel(cc),
+		maxTokens:      cc.MaxTokens,
+		temperature:    cc.Temperature,
+		assistant:      ac,
+	}
 }
 
 func (c geminiClient) APIKey() string {
@@ -35,7 +51,50 @@ func (c geminiClient) convertPrompt(p Prompt, data any) (*genai.Content, error)
 	return genai.NewContentFromParts(parts, genai.RoleUser), nil
 }
 
+// config returns the generation config with the configured temperature and
+// maximum response length
+func (c geminiClient) config() *genai.GenerateContentConfig {
+	config := &genai.GenerateContentConfig{}
+
+	if c.maxTokens > 0 {
+		config.MaxOutputTokens = int32(c.maxTokens)
+	}
+
+	if c.temperature != nil {
+		config.Temperature = genai.Ptr(float32(*c.temperature))
+	}
+
+	return config
+}
+
+func (c geminiClient) RenderPrompt(p Prompt, data any) ([]Part, error) {
+	content, err := c.convertPrompt(p, data)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := make([]Part, 0, len(content.Parts))
+
+	for _, part := range content.Parts {
+		parts = append(parts, Part{Role: content.Role, Text: part.Text})
+	}
+
+	return parts, nil
+}
+
 func (c geminiClient) GeneratePrompt(ctx context.Context, p Prompt, data any) (string, error) {
+	return c.generate(ctx, p, data, c.config())
+}
+
+func (c geminiClient) GenerateJSON(ctx context.Context, p Prompt, data any, schema *Schema) (string, error) {
+	config := c.config()
+	config.ResponseMIMEType = "application/json"
+	config.ResponseSchema = schema.genai()
+
+	return c.generate(ctx, p, data, config)
+}
+
+func (c geminiClient) generate(ctx context.Context, p Prompt, data any, config *genai.GenerateContentConfig) (string, error) {
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{APIKey: c.apiKey})
 	if err != nil {
 		return "", err
@@ -46,13 +105,13 @@ func (c geminiClient) GeneratePrompt(ctx context.Context, p Prompt, data any) (s
 		return "", err
 	}
 
-	config := &genai.GenerateContentConfig{}
-
 	result, err := client.Models.GenerateContent(ctx, c.model, []*genai.Content{prompt}, config)
 	if err != nil {
 		return "", err
 	}
 
+	c.reportUsage(ctx, result.UsageMetadata)
+
 	for _, c := range result.Candidates {
 		if len(c.Content.Parts) == 0 {
 			continue
@@ -65,3 +124,236 @@ func (c geminiClient) GeneratePrompt(ctx context.Context, p Prompt, data any) (s
 
 	return "", nil
 }
+
+func (c geminiClient) StreamPrompt(ctx context.Context, p Prompt, data any, fn StreamFunc) (string, error) {
+	client, err := genai.NewClient(ctx, &genai.ClientConfig{APIKey: c.apiKey})
+	if err != nil {
+		return "", err
+	}
+
+	prompt, err := c.convertPrompt(p, data)
+	if err != nil {
+		return "", err
+	}
+
+	config := c.config()
+
+	var (
+		result strings.Builder
+		usage  *genai.GenerateContentResponseUsageMetadata
+	)
+
+	defer func() { c.reportUsage(ctx, usage) }()
+
+	for resp, err := range client.Models.GenerateContentStream(ctx, c.model, []*genai.Content{prompt}, config) {
+		if err != nil {
+			return "", err
+		}
+
+		if resp.UsageMetadata != nil {
+			usage = resp.UsageMetadata
+		}
+
+		chunk := resp.Text()
+		if chunk == "" {
+			continue
+		}
+
+		result.WriteString(chunk)
+
+		if err := fn(chunk); err != nil {
+			return "", err
+		}
+	}
+
+	return result.String(), nil
+}
+
+func (c geminiClient) convertConversation(conv *Conversation) ([]*genai.Content, *genai.GenerateContentConfig) {
+	system := make([]*genai.Part, 0, len(conv.System)+1)
+
+	for _, part := range conv.System {
+		system = append(system, &genai.Part{Text: part})
+	}
+
+	system = append(system, &genai.Part{Text: conv.Context})
+
+	config := c.config()
+	config.SystemInstruction = genai.NewContentFromParts(system, genai.RoleUser)
+	config.Tools = c.convertTools(conv.Tools)
+
+	contents := make([]*genai.Content, 0, len(conv.Messages))
+
+	for _, m := range conv.Messages {
+		role := genai.Role(genai.RoleUser)
+		if m.Role == RoleAssistant {
+			role = genai.RoleModel
+		}
+
+		contents = append(contents, genai.NewContentFromText(m.Content, role))
+	}
+
+	return contents, config
+}
+
+func (c geminiClient) convertTools(tools []Tool) []*genai.Tool {
+	if len(tools) == 0 {
+		return nil
+	}
+
+	declarations := make([]*genai.FunctionDeclaration, 0, len(tools))
+
+	for _, t := range tools {
+		schema := &genai.Schema{
+			Type:       genai.TypeObject,
+			Properties: map[string]*genai.Schema{},
+		}
+
+		for _, p := range t.Parameters {
+			schema.Properties[p.Name] = &genai.Schema{
+				Type:        genai.Type(strings.ToUpper(p.Type)),
+				Description: p.Description,
+			}
+
+			if p.Required {
+				schema.Required = append(schema.Required, p.Name)
+			}
+		}
+
+		declarations = append(declarations, &genai.FunctionDeclaration{
+			Name:        t.Name,
+			Description: t.Description,
+			Parameters:  schema,
+		})
+	}
+
+	return []*genai.Tool{{FunctionDeclarations: declarations}}
+}
+
+func (c geminiClient) StreamChat(ctx context.Context, conv *Conversation, fn StreamFunc) (string, error) {
+	client, err := genai.NewClient(ctx, &genai.ClientConfig{APIKey: c.apiKey})
+	if err != nil {
+		return "", err
+	}
+
+	contents, config := c.convertConversation(conv)
+
+	var result strings.Builder
+
+	for range maxToolRounds {
+		var (
+			parts []*genai.Part
+			usage *genai.GenerateContentResponseUsageMetadata
+		)
+
+		for resp, err := range client.Models.GenerateContentStream(ctx, c.model, contents, config) {
+			if err != nil {
+				return "", err
+			}
+
+			if resp.UsageMetadata != nil {
+				usage = resp.UsageMetadata
+			}
+
+			if len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil {
+				continue
+			}
+
+			for _, part := range resp.Candidates[0].Content.Parts {
+				parts = append(parts, part)
+
+				if part.Text == "" || part.Thought {
+					continue
+				}
+
+				result.WriteString(part.Text)
+
+				if err := fn(part.Text); err != nil {
+					return "", err
+				}
+			}
+		}
+
+		c.reportUsage(ctx, usage)
+
+		responses := c.callTools(ctx, conv, parts)
+		if len(responses) == 0 {
+			return result.String(), nil
+		}
+
+		contents = append(contents,
+			genai.NewContentFromParts(parts, genai.RoleModel),
+			genai.NewContentFromParts(responses, genai.RoleUser),
+		)
+	}
+
+	return "", ErrTooManyToolCalls
+}
+
+// callTools runs the function calls in the model's response and returns the
+// function responses
+func (c geminiClient) callTools(ctx context.Context, conv *Conversation, parts []*genai.Part) []*genai.Part {
+	var responses []*genai.Part
+
+	for _, part := range parts {
+		fc := part.FunctionCall
+		if fc == nil {
+			continue
+		}
+
+		output := json.RawMessage(conv.callTool(ctx, fc.Name, fc.Args))
+
+		responses = append(responses, &genai.Part{
+			FunctionResponse: &genai.FunctionResponse{
+				ID:       fc.ID,
+				Name:     fc.Name,
+				Response: map[string]any{"output": output},
+			},
+		})
+	}
+
+	return responses
+}
+
+func (c geminiClient) EmbeddingModel() string {
+	return c.embeddingModel
+}
+
+func (c geminiClient) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	client, err := genai.NewClient(ctx, &genai.ClientConfig{APIKey: c.apiKey})
+	if err != nil {
+		return nil, err
+	}
+
+	contents := make([]*genai.Content, 0, len(texts))
+
+	for _, t := range texts {
+		contents = append(contents, genai.NewContentFromText(t, genai.RoleUser))
+	}
+
+	result, err := client.Models.EmbedContent(ctx, c.embeddingModel, contents, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	vectors := make([][]float32, 0, len(result.Embeddings))
+
+	for _, e := range result.Embeddings {
+		vectors = append(vectors, e.Values)
+	}
+
+	return vectors, nil
+}
+
+func (c geminiClient) reportUsage(ctx context.Context, m *genai.GenerateContentResponseUsageMetadata) {
+	if m == nil {
+		return
+	}
+
+	ReportUsage(ctx, Usage{
+		Provider:         "gemini",
+		Model:            c.model,
+		PromptTokens:     int(m.PromptTokenCount),
+		CompletionTokens: int(m.CandidatesTokenCount + m.ThoughtsTokenCount),
+	})
+}
@@ -2,15 +2,35 @@ package ai
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/url"
 	"strings"
 
 	"github.com/ollama/ollama/api"
 )
 
 type ollamaClient struct {
-	model     string
-	assistant AssistantConfig
+	host        string
+	model       string
+	embedding   string
+	numCtx      int
+	maxTokens   int
+	temperature *float64
+	assistant   AssistantConfig
+}
+
+func newOllamaClient(cc *AIConfig, ac AssistantConfig) ollamaClient {
+	return ollamaClient{
+		host:        cc.Host,
+		model:       cc.Model,
+		embedding:   embeddingModel(cc),
+		numCtx:      cc.NumCtx,
+		maxTokens:   cc.MaxTokens,
+		temperature: cc.Temperature,
+		assistant:   ac,
+	}
 }
 
 func (c ollamaClient) APIKey() string {
@@ -21,44 +41,271 @@ func (c ollamaClient) Model() string {
 	return c.model
 }
 
-func (c ollamaClient) convertPrompt(p Prompt, data any) (string, error) {
+// client returns a client for the configured host, falling back to the
+// OLLAMA_HOST environment variable
+func (c ollamaClient) client() (*api.Client, error) {
+	if c.host == "" {
+		client, err := api.ClientFromEnvironment()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create ollama client from environment: %w", err)
+		}
+
+		return client, nil
+	}
+
+	u, err := ollamaURL(c.host)
+	if err != nil {
+		return nil, err
+	}
+
+	return api.NewClient(u, http.DefaultClient), nil
+}
+
+// ollamaURL parses the host, which may leave out the http:// scheme, like
+// localhost:11434
+func ollamaURL(host string) (*url.URL, error) {
+	if !strings.Contains(host, "://") {
+		host = "http://" + host
+	}
+
+	u, err := url.Parse(host)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ollama host %q: %w", host, err)
+	}
+
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid ollama host %q: expected eg. http://localhost:11434", host)
+	}
+
+	return u, nil
+}
+
+func (c ollamaClient) options() map[string]any {
+	o := map[string]any{}
+
+	if c.numCtx > 0 {
+		o["num_ctx"] = c.numCtx
+	}
+
+	if c.maxTokens > 0 {
+		o["num_predict"] = c.maxTokens
+	}
+
+	if c.temperature != nil {
+		o["temperature"] = *c.temperature
+	}
+
+	return o
+}
+
+func (c ollamaClient) convertPrompt(p Prompt, data any) ([]api.Message, error) {
 	prompt, err := p(c.assistant, data)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return strings.Join(prompt, "\n"), nil
+	return []api.Message{
+		{Role: "user", Content: strings.Join(prompt, "\n")},
+	}, nil
+}
+
+func (c ollamaClient) RenderPrompt(p Prompt, data any) ([]Part, error) {
+	messages, err := c.convertPrompt(p, data)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := make([]Part, 0, len(messages))
+
+	for _, m := range messages {
+		parts = append(parts, Part{Role: m.Role, Text: m.Content})
+	}
+
+	return parts, nil
 }
 
 func (c ollamaClient) GeneratePrompt(ctx context.Context, p Prompt, data any) (string, error) {
-	prompt, err := c.convertPrompt(p, data)
+	return c.StreamPrompt(ctx, p, data, func(string) error { return nil })
+}
+
+func (c ollamaClient) StreamPrompt(ctx context.Context, p Prompt, data any, fn StreamFunc) (string, error) {
+	messages, err := c.convertPrompt(p, data)
 	if err != nil {
 		return "", err
 	}
 
-	client, err := api.ClientFromEnvironment()
+	msg, err := c.chat(ctx, messages, nil, nil, fn)
 	if err != nil {
-		return "", fmt.Errorf("failed to create ollama client from environment: %w", err)
+		return "", err
 	}
 
-	req := &api.GenerateRequest{
-		Model:  c.Model(),
-		Prompt: prompt,
-		// Options can be added here if needed, potentially from config
+	return msg.Content, nil
+}
+
+func (c ollamaClient) GenerateJSON(ctx context.Context, p Prompt, data any, schema *Schema) (string, error) {
+	messages, err := c.convertPrompt(p, data)
+	if err != nil {
+		return "", err
 	}
 
-	var result string
+	format, err := json.Marshal(schema.JSONSchema())
+	if err != nil {
+		return "", err
+	}
 
-	respFunc := func(resp api.GenerateResponse) error {
-		// Only print the response here; GenerateResponse has a number of other
-		// interesting fields you want to examine.
-		result = resp.Response
-		return nil
+	msg, err := c.chat(ctx, messages, nil, format, func(string) error { return nil })
+	if err != nil {
+		return "", err
 	}
 
-	if err := client.Generate(ctx, req, respFunc); err != nil {
+	return msg.Content, nil
+}
+
+func (c ollamaClient) convertConversation(conv *Conversation) []api.Message {
+	messages := make([]api.Message, 0, len(conv.Messages)+2)
+
+	messages = append(messages,
+		api.Message{Role: "system", Content: strings.Join(conv.System, "\n")},
+		api.Message{Role: "system", Content: conv.Context},
+	)
+
+	for _, m := range conv.Messages {
+		messages = append(messages, api.Message{Role: string(m.Role), Content: m.Content})
+	}
+
+	return messages
+}
+
+func (c ollamaClient) convertTools(tools []Tool) (api.Tools, error) {
+	result := make(api.Tools, 0, len(tools))
+
+	for _, t := range tools {
+		j, err := json.Marshal(map[string]any{
+			"type": "function",
+			"function": map[string]any{
+				"name":        t.Name,
+				"description": t.Description,
+				"parameters":  t.JSONSchema(),
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		var tool api.Tool
+
+		if err := json.Unmarshal(j, &tool); err != nil {
+			return nil, err
+		}
+
+		result = append(result, tool)
+	}
+
+	return result, nil
+}
+
+func (c ollamaClient) StreamChat(ctx context.Context, conv *Conversation, fn StreamFunc) (string, error) {
+	tools, err := c.convertTools(conv.Tools)
+	if err != nil {
 		return "", err
 	}
 
+	messages := c.convertConversation(conv)
+
+	var result strings.Builder
+
+	for range maxToolRounds {
+		msg, err := c.chat(ctx, messages, tools, nil, fn)
+		if err != nil {
+			return "", err
+		}
+
+		result.WriteString(msg.Content)
+
+		if len(msg.ToolCalls) == 0 {
+			return result.String(), nil
+		}
+
+		messages = append(messages, msg)
+
+		for _, tc := range msg.ToolCalls {
+			messages = append(messages, api.Message{
+				Role:    "tool",
+				Content: conv.callTool(ctx, tc.Function.Name, tc.Function.Arguments),
+			})
+		}
+	}
+
+	return "", ErrTooManyToolCalls
+}
+
+// chat sends the messages to the chat endpoint and collects all streamed
+// chunks and tool calls into a single assistant message. The format is an
+// optional JSON schema for the response.
+func (c ollamaClient) chat(ctx context.Context, messages []api.Message, tools api.Tools, format json.RawMessage, fn StreamFunc) (api.Message, error) {
+	result := api.Message{Role: "assistant"}
+
+	client, err := c.client()
+	if err != nil {
+		return result, err
+	}
+
+	req := &api.ChatRequest{
+		Model:    c.Model(),
+		Messages: messages,
+		Tools:    tools,
+		Format:   format,
+		Options:  c.options(),
+	}
+
+	var content strings.Builder
+
+	respFunc := func(resp api.ChatResponse) error {
+		result.ToolCalls = append(result.ToolCalls, resp.Message.ToolCalls...)
+
+		if resp.Done {
+			ReportUsage(ctx, Usage{
+				Provider:         "ollama",
+				Model:            c.Model(),
+				PromptTokens:     resp.PromptEvalCount,
+				CompletionTokens: resp.EvalCount,
+			})
+		}
+
+		if resp.Message.Content == "" {
+			return nil
+		}
+
+		content.WriteString(resp.Message.Content)
+
+		return fn(resp.Message.Content)
+	}
+
+	if err := client.Chat(ctx, req, respFunc); err != nil {
+		return result, err
+	}
+
+	result.Content = content.String()
+
 	return result, nil
 }
+
+func (c ollamaClient) EmbeddingModel() string {
+	return c.embedding
+}
+
+func (c ollamaClient) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	client, err := c.client()
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := client.Embed(ctx, &api.EmbedRequest{Model: c.embedding, Input: texts})
+	if err != nil {
+		return nil, err
+	}
+
+	ReportUsage(ctx, Usage{Provider: "ollama", Model: c.embedding, PromptTokens: result.PromptEvalCount})
+
+	return result.Embeddings, nil
+}
@@ -0,0 +1,205 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ollama/ollama/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testPrompt(_ AssistantConfig, _ any) ([]string, error) {
+	return []string{"Say hello"}, nil
+}
+
+func TestOllamaClient_StreamPrompt(t *testing.T) {
+	chunks := []string{"Good ", "morning, ", "sir."}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/chat", r.URL.Path, "Unexpected endpoint")
+
+		enc := json.NewEncoder(w)
+
+		for i, c := range chunks {
+			require.NoError(t, enc.Encode(api.ChatResponse{
+				Model:   "llama3",
+				Message: api.Message{Role: "assistant", Content: c},
+				Done:    i == len(chunks)-1,
+			}))
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("OLLAMA_HOST", server.URL)
+
+	client := ollamaClient{model: "llama3"}
+
+	var received []string
+
+	result, err := client.StreamPrompt(context.Background(), testPrompt, nil, func(chunk string) error {
+		received = append(received, chunk)
+		return nil
+	})
+
+	require.NoError(t, err, "Did not expect an error")
+	assert.Equal(t, chunks, received, "Chunks should be passed on in order")
+	assert.Equal(t, "Good morning, sir.", result, "Result should contain all chunks")
+}
+
+func TestOllamaClient_StreamChat(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/chat", r.URL.Path, "Unexpected endpoint")
+
+		var req api.ChatRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		roles := make([]string, 0, len(req.Messages))
+		for _, m := range req.Messages {
+			roles = append(roles, m.Role)
+		}
+
+		assert.Equal(t, []string{"system", "system", "user", "assistant", "user"}, roles, "Unexpected message roles")
+		assert.Equal(t, "And tomorrow?", req.Messages[4].Content, "Last message should be the new question")
+
+		require.NoError(t, json.NewEncoder(w).Encode(api.ChatResponse{
+			Model:   "llama3",
+			Message: api.Message{Role: "assistant", Content: "Rain, sir."},
+			Done:    true,
+		}))
+	}))
+	defer server.Close()
+
+	t.Setenv("OLLAMA_HOST", server.URL)
+
+	client := ollamaClient{model: "llama3"}
+
+	conv, err := NewConversation(AssistantConfig{Name: "Spark"}, map[string]string{"weather": "rain"})
+	require.NoError(t, err)
+
+	conv.AddUser("What is the weather today?")
+	conv.AddAssistant("Sunny, sir.")
+	conv.AddUser("And tomorrow?")
+
+	result, err := client.StreamChat(context.Background(), conv, func(string) error { return nil })
+
+	require.NoError(t, err, "Did not expect an error")
+	assert.Equal(t, "Rain, sir.", result)
+}
+
+func TestOllamaClient_GeneratePrompt(t *testing.T) {
+	chunks := []string{"Good ", "morning, ", "sir."}
+	temperature := 0.2
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/chat", r.URL.Path, "Unexpected endpoint")
+
+		var req api.ChatRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		assert.Equal(t, "llama3", req.Model)
+		assert.Equal(t, map[string]any{"num_ctx": float64(16384), "temperature": 0.2}, req.Options, "Options should be passed on")
+
+		enc := json.NewEncoder(w)
+
+		for i, c := range chunks {
+			require.NoError(t, enc.Encode(api.ChatResponse{
+				Model:   "llama3",
+				Message: api.Message{Role: "assistant", Content: c},
+				Done:    i == len(chunks)-1,
+			}))
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("OLLAMA_HOST", "http://127.0.0.1:1")
+
+	client, err := NewClient(&AIConfig{
+		Type:        "ollama",
+		Model:       "llama3",
+		Host:        server.URL,
+		NumCtx:      16384,
+		Temperature: &temperature,
+	}, AssistantConfig{})
+	require.NoError(t, err)
+
+	result, err := client.GeneratePrompt(context.Background(), testPrompt, nil)
+
+	require.NoError(t, err, "Did not expect an error")
+	assert.Equal(t, "Good morning, sir.", result, "Result should contain all chunks, not only the last one")
+}
+
+func TestOllamaClient_InvalidHost(t *testing.T) {
+	client := ollamaClient{host: "://invalid", model: "llama3"}
+
+	_, err := client.GeneratePrompt(context.Background(), testPrompt, nil)
+	assert.ErrorContains(t, err, "invalid ollama host")
+}
+
+func Test_ollamaURL(t *testing.T) {
+	for host, expected := range map[string]string{
+		"localhost:11434":            "http://localhost:11434",
+		"http://ollama:11434":        "http://ollama:11434",
+		"https://ollama.example.org": "https://ollama.example.org",
+	} {
+		u, err := ollamaURL(host)
+		require.NoError(t, err, host)
+		assert.Equal(t, expected, u.String())
+	}
+
+	for _, host := range []string{"://invalid", "ftp://ollama:11434", "http://"} {
+		_, err := ollamaURL(host)
+		assert.ErrorContains(t, err, "invalid ollama host", host)
+	}
+}
+
+func TestOllamaClient_StreamChat_Tools(t *testing.T) {
+	requests := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req api.ChatRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		requests++
+
+		require.Len(t, req.Tools, 1, "Tools should be sent")
+		assert.Equal(t, "echo", req.Tools[0].Function.Name)
+
+		resp := api.ChatResponse{Model: "llama3", Done: true}
+
+		if requests == 1 {
+			resp.Message = api.Message{
+				Role: "assistant",
+				ToolCalls: []api.ToolCall{
+					{Function: api.ToolCallFunction{Name: "echo", Arguments: map[string]any{"text": "dentist"}}},
+				},
+			}
+		} else {
+			last := req.Messages[len(req.Messages)-1]
+			assert.Equal(t, "tool", last.Role, "Tool result should be sent back")
+			assert.JSONEq(t, `{"echo":"dentist"}`, last.Content)
+
+			resp.Message = api.Message{Role: "assistant", Content: "The dentist is on Monday."}
+		}
+
+		require.NoError(t, json.NewEncoder(w).Encode(resp))
+	}))
+	defer server.Close()
+
+	client := ollamaClient{host: server.URL, model: "llama3"}
+
+	conv, err := NewConversation(AssistantConfig{Name: "Spark"}, nil)
+	require.NoError(t, err)
+
+	conv.Tools = []Tool{testTool()}
+	conv.AddUser("When is the dentist?")
+
+	result, err := client.StreamChat(context.Background(), conv, func(string) error { return nil })
+
+	require.NoError(t, err, "Did not expect an error")
+	assert.Equal(t, 2, requests, "Expected a second request with the tool result")
+	assert.Equal(t, "The dentist is on Monday.", result)
+}
@@ -2,15 +2,44 @@ package ai
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
 
 	"github.com/openai/openai-go"
 	"github.com/openai/openai-go/option"
+	"github.com/openai/openai-go/shared"
 )
 
 type openaiClient struct {
-	apiKey    string
-	model     string
-	assistant AssistantConfig
+	apiKey       string
+	model        string
+	baseURL      string
+	headers      map[string]string
+	organization string
+	project      string
+	embedding    string
+	maxTokens    int
+	temperature  *float64
+	streamUsage  bool
+	assistant    AssistantConfig
+	chained      bool
+}
+
+func newOpenAIClient(cc *AIConfig, ac AssistantConfig) openaiClient {
+	return openaiClient{
+		apiKey:       cc.APIKey,
+		model:        cc.Model,
+		baseURL:      cc.BaseURL,
+		headers:      cc.Headers,
+		organization: cc.Organization,
+		project:      cc.Project,
+		embedding:    embeddingModel(cc),
+		maxTokens:    cc.MaxTokens,
+		temperature:  cc.Temperature,
+		streamUsage:  cc.StreamUsage == nil || *cc.StreamUsage,
+		assistant:    ac,
+	}
 }
 
 func (c openaiClient) APIKey() string {
@@ -36,24 +65,117 @@ func (c openaiClient) convertPrompt(p Prompt, data any) (openai.ChatCompletionMe
 	return openai.UserMessage(parts), nil
 }
 
-func (c openaiClient) GeneratePrompt(ctx context.Context, p Prompt, data any) (string, error) {
-	prompt, err := c.convertPrompt(p, data)
+func (c openaiClient) RenderPrompt(p Prompt, data any) ([]Part, error) {
+	message, err := c.convertPrompt(p, data)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+
+	content := message.OfUser.Content.OfArrayOfContentParts
+	parts := make([]Part, 0, len(content))
+
+	for _, part := range content {
+		parts = append(parts, Part{Role: "user", Text: part.OfText.Text})
 	}
 
-	client := openai.NewClient(
+	return parts, nil
+}
+
+func (c openaiClient) options() []option.RequestOption {
+	opts := []option.RequestOption{
 		option.WithAPIKey(c.APIKey()),
-	)
+	}
 
-	result, err := client.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
+	if c.baseURL != "" {
+		opts = append(opts, option.WithBaseURL(c.baseURL))
+	}
+
+	if c.organization != "" {
+		opts = append(opts, option.WithOrganization(c.organization))
+	}
+
+	if c.project != "" {
+		opts = append(opts, option.WithProject(c.project))
+	}
+
+	if c.chained {
+		opts = append(opts, option.WithMaxRetries(0))
+	}
+
+	for k, v := range c.headers {
+		opts = append(opts, option.WithHeader(k, v))
+	}
+
+	return opts
+}
+
+func (c openaiClient) client() openai.Client {
+	return openai.NewClient(c.options()...)
+}
+
+func (c openaiClient) params(p Prompt, data any) (openai.ChatCompletionNewParams, error) {
+	prompt, err := c.convertPrompt(p, data)
+	if err != nil {
+		return openai.ChatCompletionNewParams{}, err
+	}
+
+	return c.withOptions(openai.ChatCompletionNewParams{
 		Messages: []openai.ChatCompletionMessageParamUnion{prompt},
 		Model:    c.Model(),
-	})
+	}), nil
+}
+
+// withOptions sets the configured temperature and maximum response length
+func (c openaiClient) withOptions(params openai.ChatCompletionNewParams) openai.ChatCompletionNewParams {
+	if c.maxTokens > 0 {
+		params.MaxCompletionTokens = openai.Int(int64(c.maxTokens))
+	}
+
+	if c.temperature != nil {
+		params.Temperature = openai.Float(*c.temperature)
+	}
+
+	return params
+}
+
+func (c openaiClient) GeneratePrompt(ctx context.Context, p Prompt, data any) (string, error) {
+	params, err := c.params(p, data)
+	if err != nil {
+		return "", err
+	}
+
+	return c.generate(ctx, params)
+}
+
+func (c openaiClient) GenerateJSON(ctx context.Context, p Prompt, data any, schema *Schema) (string, error) {
+	params, err := c.params(p, data)
+	if err != nil {
+		return "", err
+	}
+
+	params.ResponseFormat = openai.ChatCompletionNewParamsResponseFormatUnion{
+		OfJSONSchema: &shared.ResponseFormatJSONSchemaParam{
+			JSONSchema: shared.ResponseFormatJSONSchemaJSONSchemaParam{
+				Name:   "response",
+				Strict: openai.Bool(true),
+				Schema: schema.JSONSchema(),
+			},
+		},
+	}
+
+	return c.generate(ctx, params)
+}
+
+func (c openaiClient) generate(ctx context.Context, params openai.ChatCompletionNewParams) (string, error) {
+	client := c.client()
+
+	result, err := client.Chat.Completions.New(ctx, params)
 	if err != nil {
 		return "", err
 	}
 
+	c.reportUsage(ctx, result.Usage)
+
 	for _, c := range result.Choices {
 		if len(c.Message.Content) == 0 {
 			continue
@@ -64,3 +186,186 @@ func (c openaiClient) GeneratePrompt(ctx context.Context, p Prompt, data any) (s
 
 	return "", nil
 }
+
+func (c openaiClient) StreamPrompt(ctx context.Context, p Prompt, data any, fn StreamFunc) (string, error) {
+	params, err := c.params(p, data)
+	if err != nil {
+		return "", err
+	}
+
+	msg, err := c.stream(ctx, params, fn)
+	if err != nil {
+		return "", err
+	}
+
+	return msg.Content, nil
+}
+
+func (c openaiClient) convertConversation(conv *Conversation) []openai.ChatCompletionMessageParamUnion {
+	messages := make([]openai.ChatCompletionMessageParamUnion, 0, len(conv.Messages)+2)
+
+	messages = append(messages,
+		openai.SystemMessage(strings.Join(conv.System, "\n")),
+		openai.SystemMessage(conv.Context),
+	)
+
+	for _, m := range conv.Messages {
+		switch m.Role {
+		case RoleAssistant:
+			messages = append(messages, openai.AssistantMessage(m.Content))
+		default:
+			messages = append(messages, openai.UserMessage(m.Content))
+		}
+	}
+
+	return messages
+}
+
+// convertTools returns nil without tools, since OpenAI rejects an empty list
+func (c openaiClient) convertTools(tools []Tool) []openai.ChatCompletionToolParam {
+	if len(tools) == 0 {
+		return nil
+	}
+
+	result := make([]openai.ChatCompletionToolParam, 0, len(tools))
+
+	for _, t := range tools {
+		result = append(result, openai.ChatCompletionToolParam{
+			Function: shared.FunctionDefinitionParam{
+				Name:        t.Name,
+				Description: openai.String(t.Description),
+				Parameters:  shared.FunctionParameters(t.JSONSchema()),
+			},
+		})
+	}
+
+	return result
+}
+
+func (c openaiClient) chatParams(conv *Conversation) openai.ChatCompletionNewParams {
+	return c.withOptions(openai.ChatCompletionNewParams{
+		Messages: c.convertConversation(conv),
+		Model:    c.Model(),
+		Tools:    c.convertTools(conv.Tools),
+	})
+}
+
+func (c openaiClient) StreamChat(ctx context.Context, conv *Conversation, fn StreamFunc) (string, error) {
+	params := c.chatParams(conv)
+
+	var result strings.Builder
+
+	for range maxToolRounds {
+		msg, err := c.stream(ctx, params, fn)
+		if err != nil {
+			return "", err
+		}
+
+		result.WriteString(msg.Content)
+
+		if len(msg.ToolCalls) == 0 {
+			return result.String(), nil
+		}
+
+		params.Messages = append(params.Messages, msg.ToParam())
+
+		for _, tc := range msg.ToolCalls {
+			var args map[string]any
+
+			if err := json.Unmarshal([]byte(tc.Function.Arguments), &args); err != nil {
+				params.Messages = append(params.Messages, openai.ToolMessage(toolError(err), tc.ID))
+				continue
+			}
+
+			params.Messages = append(params.Messages, openai.ToolMessage(conv.callTool(ctx, tc.Function.Name, args), tc.ID))
+		}
+	}
+
+	return "", ErrTooManyToolCalls
+}
+
+// stream passes every chunk of content to fn and returns the accumulated
+// message, including any tool calls
+func (c openaiClient) stream(ctx context.Context, params openai.ChatCompletionNewParams, fn StreamFunc) (openai.ChatCompletionMessage, error) {
+	client := c.client()
+
+	if c.streamUsage {
+		params.StreamOptions = openai.ChatCompletionStreamOptionsParam{IncludeUsage: openai.Bool(true)}
+	}
+
+	stream := client.Chat.Completions.NewStreaming(ctx, params)
+	defer stream.Close()
+
+	var acc openai.ChatCompletionAccumulator
+
+	defer func() { c.reportUsage(ctx, acc.Usage) }()
+
+	for stream.Next() {
+		chunk := stream.Current()
+		acc.AddChunk(chunk)
+
+		for _, choice := range chunk.Choices {
+			if choice.Delta.Content == "" {
+				continue
+			}
+
+			if err := fn(choice.Delta.Content); err != nil {
+				return openai.ChatCompletionMessage{}, err
+			}
+		}
+	}
+
+	if err := stream.Err(); err != nil {
+		return openai.ChatCompletionMessage{}, err
+	}
+
+	if len(acc.Choices) == 0 {
+		return openai.ChatCompletionMessage{}, nil
+	}
+
+	return acc.Choices[0].Message, nil
+}
+
+func (c openaiClient) EmbeddingModel() string {
+	return c.embedding
+}
+
+func (c openaiClient) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	client := c.client()
+
+	result, err := client.Embeddings.New(ctx, openai.EmbeddingNewParams{
+		Input: openai.EmbeddingNewParamsInputUnion{OfArrayOfStrings: texts},
+		Model: c.embedding,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ReportUsage(ctx, Usage{Provider: "openai", Model: c.embedding, PromptTokens: int(result.Usage.PromptTokens)})
+
+	vectors := make([][]float32, len(result.Data))
+
+	for _, d := range result.Data {
+		if d.Index < 0 || d.Index >= int64(len(vectors)) {
+			return nil, fmt.Errorf("invalid embedding index %d for %d vectors", d.Index, len(vectors))
+		}
+
+		v := make([]float32, len(d.Embedding))
+		for i, x := range d.Embedding {
+			v[i] = float32(x)
+		}
+
+		vectors[d.Index] = v
+	}
+
+	return vectors, nil
+}
+
+func (c openaiClient) reportUsage(ctx context.Context, u openai.CompletionUsage) {
+	ReportUsage(ctx, Usage{
+		Provider:         "openai",
+		Model:            c.Model(),
+		PromptTokens:     int(u.PromptTokens),
+		CompletionTokens: int(u.CompletionTokens),
+	})
+}
@@ -0,0 +1,223 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newOpenAIStandIn returns a server that behaves like an OpenAI-compatible
+// chat completions endpoint below /v1
+func newOpenAIStandIn(t *testing.T, chunks []string) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/chat/completions", r.URL.Path, "Unexpected endpoint")
+		assert.Equal(t, "Bearer local-key", r.Header.Get("Authorization"), "API key should be sent")
+		assert.Equal(t, "my-org", r.Header.Get("OpenAI-Organization"), "Organization should be sent")
+		assert.Equal(t, "my-project", r.Header.Get("OpenAI-Project"), "Project should be sent")
+		assert.Equal(t, "gateway", r.Header.Get("X-Gateway"), "Extra headers should be sent")
+
+		var req struct {
+			Model  string `json:"model"`
+			Stream bool   `json:"stream"`
+		}
+
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "local-model", req.Model)
+
+		if !req.Stream {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"id":"1","object":"chat.completion","created":0,"model":"local-model","choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":%q}}]}`, "Hello there.")
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+
+		for _, c := range chunks {
+			fmt.Fprintf(w, "data: {\"id\":\"1\",\"object\":\"chat.completion.chunk\",\"created\":0,\"model\":\"local-model\",\"choices\":[{\"index\":0,\"delta\":{\"content\":%q}}]}\n\n", c)
+		}
+
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+}
+
+func newTestOpenAIClient(baseURL string) openaiClient {
+	return newOpenAIClient(&AIConfig{
+		Type:         "openai",
+		APIKey:       "local-key",
+		Model:        "local-model",
+		BaseURL:      baseURL + "/v1",
+		Headers:      map[string]string{"X-Gateway": "gateway"},
+		Organization: "my-org",
+		Project:      "my-project",
+	}, AssistantConfig{})
+}
+
+func TestOpenAIClient_GeneratePrompt(t *testing.T) {
+	server := newOpenAIStandIn(t, nil)
+	defer server.Close()
+
+	client := newTestOpenAIClient(server.URL)
+
+	result, err := client.GeneratePrompt(context.Background(), testPrompt, nil)
+
+	require.NoError(t, err, "Did not expect an error")
+	assert.Equal(t, "Hello there.", result)
+}
+
+func TestOpenAIClient_StreamPrompt(t *testing.T) {
+	chunks := []string{"Good ", "morning, ", "sir."}
+
+	server := newOpenAIStandIn(t, chunks)
+	defer server.Close()
+
+	client := newTestOpenAIClient(server.URL)
+
+	var received []string
+
+	result, err := client.StreamPrompt(context.Background(), testPrompt, nil, func(chunk string) error {
+		received = append(received, chunk)
+		return nil
+	})
+
+	require.NoError(t, err, "Did not expect an error")
+	assert.Equal(t, chunks, received, "Chunks should be passed on in order")
+	assert.Equal(t, "Good morning, sir.", result, "Result should contain all chunks")
+}
+
+func TestOpenAIClient_StreamChat(t *testing.T) {
+	server := newOpenAIStandIn(t, []string{"Rain, ", "sir."})
+	defer server.Close()
+
+	client := newTestOpenAIClient(server.URL)
+
+	conv, err := NewConversation(AssistantConfig{Name: "Spark"}, nil)
+	require.NoError(t, err)
+
+	conv.AddUser("And tomorrow?")
+
+	result, err := client.StreamChat(context.Background(), conv, func(string) error { return nil })
+
+	require.NoError(t, err, "Did not expect an error")
+	assert.Equal(t, "Rain, sir.", result)
+}
+
+func TestOpenAIClient_chatParams_Tools(t *testing.T) {
+	client := newTestOpenAIClient("http://localhost")
+
+	conv := &Conversation{System: []string{"Be brief."}}
+
+	b, err := json.Marshal(client.chatParams(conv))
+	require.NoError(t, err)
+	assert.NotContains(t, string(b), `"tools"`, "An empty tools list is rejected by OpenAI")
+
+	conv.Tools = []Tool{{Name: "search_entries", Description: "Search"}}
+
+	b, err = json.Marshal(client.chatParams(conv))
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"tools":[`)
+}
+
+func TestOpenAIClient_GenerateJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ResponseFormat struct {
+				Type       string `json:"type"`
+				JSONSchema struct {
+					Name   string         `json:"name"`
+					Strict bool           `json:"strict"`
+					Schema map[string]any `json:"schema"`
+				} `json:"json_schema"`
+			} `json:"response_format"`
+		}
+
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		assert.Equal(t, "json_schema", req.ResponseFormat.Type)
+		assert.True(t, req.ResponseFormat.JSONSchema.Strict, "Schema should be strict")
+		assert.Equal(t, "object", req.ResponseFormat.JSONSchema.Schema["type"])
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"id":"1","object":"chat.completion","created":0,"model":"local-model","choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":%q}}]}`, `{"greeting":"Hello"}`)
+	}))
+	defer server.Close()
+
+	client := newTestOpenAIClient(server.URL)
+
+	result, err := client.GenerateJSON(context.Background(), testPrompt, nil, SummarySchema)
+
+	require.NoError(t, err, "Did not expect an error")
+	assert.JSONEq(t, `{"greeting":"Hello"}`, result)
+}
+
+func TestOpenAIClient_Usage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]any
+
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, map[string]any{"include_usage": true}, req["stream_options"], "Usage should be requested")
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"id\":\"1\",\"object\":\"chat.completion.chunk\",\"created\":0,\"model\":\"local-model\",\"choices\":[{\"index\":0,\"delta\":{\"content\":\"Hello.\"}}]}\n\n")
+		fmt.Fprint(w, "data: {\"id\":\"1\",\"object\":\"chat.completion.chunk\",\"created\":0,\"model\":\"local-model\",\"choices\":[],\"usage\":{\"prompt_tokens\":80,\"completion_tokens\":12,\"total_tokens\":92}}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	client := newTestOpenAIClient(server.URL)
+
+	var usage []Usage
+
+	ctx := WithUsageFunc(context.Background(), func(u Usage) { usage = append(usage, u) })
+
+	_, err := client.StreamPrompt(ctx, testPrompt, nil, func(string) error { return nil })
+
+	require.NoError(t, err, "Did not expect an error")
+	assert.Equal(t, []Usage{{Provider: "openai", Model: "local-model", PromptTokens: 80, CompletionTokens: 12}}, usage)
+}
+
+func TestOpenAIClient_StreamUsageDisabled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]any
+
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.NotContains(t, req, "stream_options", "Usage should not be requested when it is turned off")
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"id\":\"1\",\"object\":\"chat.completion.chunk\",\"created\":0,\"model\":\"local-model\",\"choices\":[{\"index\":0,\"delta\":{\"content\":\"Hello.\"}}]}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	client := newTestOpenAIClient(server.URL)
+	client.streamUsage = false
+
+	result, err := client.StreamPrompt(context.Background(), testPrompt, nil, func(string) error { return nil })
+
+	require.NoError(t, err, "Did not expect an error")
+	assert.Equal(t, "Hello.", result)
+}
+
+func TestOpenAIClient_RenderPrompt(t *testing.T) {
+	client := newTestOpenAIClient("")
+
+	p := func(AssistantConfig, any) ([]string, error) {
+		return []string{"You are Spark.", "Show the agenda"}, nil
+	}
+
+	parts, err := client.RenderPrompt(p, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, []Part{
+		{Role: "user", Text: "You are Spark."},
+		{Role: "user", Text: "Show the agenda"},
+	}, parts, "Every part should be rendered separately")
+}
@@ -0,0 +1,74 @@
+package ai
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// providers are the types of LLM that NewClient supports
+var providers = []string{"gemini", "openai", "anthropic", "ollama", "replay", "fake"}
+
+// Validate checks the persona's name, style and preferences
+func (a AssistantConfig) Validate() error {
+	var errs []error
+
+	if a.Name == "" {
+		errs = append(errs, errors.New("name is missing"))
+	}
+
+	if a.Style == "" {
+		errs = append(errs, errors.New("style is empty"))
+	}
+
+	if t := a.Temperature; t != nil && (*t < 0 || *t > 2) {
+		errs = append(errs, fmt.Errorf("temperature %g is not between 0 and 2", *t))
+	}
+
+	if a.MaxTokens < 0 {
+		errs = append(errs, fmt.Errorf("max tokens %d is negative", a.MaxTokens))
+	}
+
+	if a.Provider != "" && !slices.Contains(providers, a.Provider) {
+		errs = append(errs, fmt.Errorf("unknown provider: %s", a.Provider))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Configure returns copies of the LLM configs with the persona's preferences:
+// the preferred provider is tried first, with the preferred model, and the
+// temperature and max tokens apply to all providers
+func (a AssistantConfig) Configure(configs []*AIConfig) ([]*AIConfig, error) {
+	result := make([]*AIConfig, 0, len(configs))
+
+	for _, cc := range configs {
+		c := *cc
+
+		if a.Temperature != nil {
+			c.Temperature = a.Temperature
+		}
+
+		if a.MaxTokens > 0 {
+			c.MaxTokens = a.MaxTokens
+		}
+
+		result = append(result, &c)
+	}
+
+	if a.Provider != "" {
+		i := slices.IndexFunc(result, func(c *AIConfig) bool { return c.Type == a.Provider })
+		if i < 0 {
+			return nil, fmt.Errorf("preferred provider is not configured: %s", a.Provider)
+		}
+
+		preferred := result[i]
+		result = append([]*AIConfig{preferred}, slices.Delete(result, i, i+1)...)
+	}
+
+	if a.Model != "" && len(result) > 0 {
+		result[0].Model = a.Model
+	}
+
+	return result, nil
+}
@@ -0,0 +1,71 @@
+package ai
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssistantConfig_Validate(t *testing.T) {
+	temperature := 0.7
+
+	valid := AssistantConfig{Name: "Spark", Style: "Be brief", Temperature: &temperature, MaxTokens: 500, Provider: "ollama"}
+	require.NoError(t, valid.Validate())
+
+	tooHot := 2.5
+	invalid := AssistantConfig{Temperature: &tooHot, MaxTokens: -1, Provider: "skynet"}
+
+	err := invalid.Validate()
+	require.Error(t, err)
+
+	for _, msg := range []string{"name is missing", "style is empty", "temperature 2.5", "max tokens -1", "unknown provider: skynet"} {
+		assert.ErrorContains(t, err, msg)
+	}
+}
+
+func TestAssistantConfig_Configure(t *testing.T) {
+	temperature := 0.2
+	configs := []*AIConfig{
+		{Type: "gemini", Model: "gemini-2.0-flash", MaxTokens: 100},
+		{Type: "ollama", Model: "llama3"},
+	}
+
+	t.Run("No preferences", func(t *testing.T) {
+		result, err := AssistantConfig{}.Configure(configs)
+		require.NoError(t, err)
+		assert.Equal(t, []*AIConfig{configs[0], configs[1]}, result)
+	})
+
+	t.Run("Preferred provider and model", func(t *testing.T) {
+		a := AssistantConfig{Provider: "ollama", Model: "mistral", Temperature: &temperature, MaxTokens: 800}
+
+		result, err := a.Configure(configs)
+		require.NoError(t, err)
+		require.Len(t, result, 2)
+
+		assert.Equal(t, "ollama", result[0].Type)
+		assert.Equal(t, "mistral", result[0].Model)
+		assert.Equal(t, "gemini-2.0-flash", result[1].Model, "Only the preferred provider should use the model")
+
+		for _, c := range result {
+			assert.Equal(t, &temperature, c.Temperature)
+			assert.Equal(t, 800, c.MaxTokens)
+		}
+
+		assert.Equal(t, "llama3", configs[1].Model, "The configs should not be changed")
+		assert.Nil(t, configs[0].Temperature)
+	})
+
+	t.Run("Unconfigured provider", func(t *testing.T) {
+		_, err := AssistantConfig{Provider: "openai"}.Configure(configs)
+		assert.ErrorContains(t, err, "not configured: openai")
+	})
+}
+
+func TestAssistantConfig_PromptPreamble_Greeting(t *testing.T) {
+	preamble := AssistantConfig{Name: "Spark", Greeting: "with a bow"}.PromptPreamble()
+	assert.Contains(t, preamble, "Greet your employers as follows: with a bow.")
+
+	assert.NotContains(t, AssistantConfig{Name: "Spark"}.PromptPreamble(), "Greet your employers as follows: .")
+}
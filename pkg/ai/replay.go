@@ -0,0 +1,242 @@
+package ai
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// defaultFakeResponse is what the fake provider answers when there is no
+// recording and no response is configured
+const defaultFakeResponse = "This is a fake response."
+
+// replayClient answers with the responses recorded in a directory, one file
+// per prompt hash. The fake provider falls back to a canned response; the
+// replay provider fails, or records the response of the wrapped provider.
+type replayClient struct {
+	dir       string
+	model     string
+	fake      bool
+	response  string
+	recorder  Client
+	assistant AssistantConfig
+}
+
+func newReplayClient(cc *AIConfig, ac AssistantConfig) (replayClient, error) {
+	c := replayClient{
+		dir:       cc.Recordings,
+		model:     cc.Model,
+		fake:      cc.Type == "fake",
+		response:  cc.Response,
+		assistant: ac,
+	}
+
+	if c.response == "" {
+		c.response = defaultFakeResponse
+	}
+
+	if c.dir == "" && (!c.fake || cc.Record != nil) {
+		return c, fmt.Errorf("the %s provider needs a recordings directory", cc.Type)
+	}
+
+	if cc.Record != nil {
+		recorder, err := NewClient(cc.Record, ac)
+		if err != nil {
+			return c, fmt.Errorf("recording provider: %w", err)
+		}
+
+		c.recorder = recorder
+	}
+
+	return c, nil
+}
+
+// EmbeddingModel is the same for replay and fake providers: words are hashed
+// into vectors, so no recordings are needed
+func (c replayClient) EmbeddingModel() string {
+	return fakeEmbeddingModel
+}
+
+func (c replayClient) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, 0, len(texts))
+
+	for _, t := range texts {
+		vectors = append(vectors, fakeEmbedding(t))
+	}
+
+	return vectors, nil
+}
+
+func (c replayClient) APIKey() string {
+	return ""
+}
+
+func (c replayClient) Model() string {
+	if c.recorder != nil {
+		return c.recorder.Model()
+	}
+
+	return c.model
+}
+
+// PromptHash returns the key of the recorded response to the prompt
+func PromptHash(prompt []string) string {
+	sum := sha256.Sum256([]byte(strings.Join(prompt, "\n")))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// conversationHash returns the key of the recorded response to the next turn
+// in the conversation
+func conversationHash(conv *Conversation) (string, error) {
+	j, err := json.Marshal(conv.Messages)
+	if err != nil {
+		return "", err
+	}
+
+	return PromptHash(slices.Concat(conv.System, []string{conv.Context, string(j)})), nil
+}
+
+func (c replayClient) GeneratePrompt(ctx context.Context, p Prompt, data any) (string, error) {
+	return c.StreamPrompt(ctx, p, data, func(string) error { return nil })
+}
+
+func (c replayClient) StreamPrompt(ctx context.Context, p Prompt, data any, fn StreamFunc) (string, error) {
+	prompt, err := p(c.assistant, data)
+	if err != nil {
+		return "", err
+	}
+
+	return c.replay(PromptHash(prompt), fn, func() (string, error) {
+		return c.recorder.StreamPrompt(ctx, p, data, fn)
+	})
+}
+
+// RenderPrompt renders the prompt for the recorder; without one, the prompt is
+// rendered as it is hashed
+func (c replayClient) RenderPrompt(p Prompt, data any) ([]Part, error) {
+	if c.recorder != nil {
+		return c.recorder.RenderPrompt(p, data)
+	}
+
+	prompt, err := p(c.assistant, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return []Part{{Role: "user", Text: strings.Join(prompt, "\n")}}, nil
+}
+
+func (c replayClient) StreamChat(ctx context.Context, conv *Conversation, fn StreamFunc) (string, error) {
+	hash, err := conversationHash(conv)
+	if err != nil {
+		return "", err
+	}
+
+	return c.replay(hash, fn, func() (string, error) {
+		return c.recorder.StreamChat(ctx, conv, fn)
+	})
+}
+
+func (c replayClient) GenerateJSON(ctx context.Context, p Prompt, data any, schema *Schema) (string, error) {
+	prompt, err := p(c.assistant, data)
+	if err != nil {
+		return "", err
+	}
+
+	j, err := json.Marshal(schema.JSONSchema())
+	if err != nil {
+		return "", err
+	}
+
+	c.response = c.jsonResponse()
+
+	return c.replay(PromptHash(append(prompt, string(j))), func(string) error { return nil }, func() (string, error) {
+		return c.recorder.GenerateJSON(ctx, p, data, schema)
+	})
+}
+
+// jsonResponse is what the fake provider answers to a structured prompt
+// without a recording: the configured response if it is JSON, or a minimal
+// summary otherwise
+func (c replayClient) jsonResponse() string {
+	if json.Valid([]byte(c.response)) {
+		return c.response
+	}
+
+	j, _ := json.Marshal(Summary{Greeting: c.response})
+
+	return string(j)
+}
+
+// replay streams the recorded response line by line. Without a recording,
+// the response is generated by the recorder and written to disk.
+func (c replayClient) replay(hash string, fn StreamFunc, record func() (string, error)) (string, error) {
+	response, err := c.recording(hash)
+
+	switch {
+	case err == nil:
+	case !errors.Is(err, fs.ErrNotExist):
+		return "", err
+	case c.recorder != nil:
+		return c.record(hash, record)
+	case c.fake:
+		response = c.response
+	default:
+		return "", fmt.Errorf("no recorded response for prompt %s in %s", hash, c.dir)
+	}
+
+	for _, line := range strings.SplitAfter(response, "\n") {
+		if line == "" {
+			continue
+		}
+
+		if err := fn(line); err != nil {
+			return "", err
+		}
+	}
+
+	return response, nil
+}
+
+func (c replayClient) path(hash string) string {
+	return filepath.Join(c.dir, hash+".md")
+}
+
+func (c replayClient) recording(hash string) (string, error) {
+	if c.dir == "" {
+		return "", fs.ErrNotExist
+	}
+
+	b, err := os.ReadFile(c.path(hash))
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+func (c replayClient) record(hash string, generate func() (string, error)) (string, error) {
+	response, err := generate()
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return "", err
+	}
+
+	if err := os.WriteFile(c.path(hash), []byte(response), 0o644); err != nil {
+		return "", err
+	}
+
+	return response, nil
+}
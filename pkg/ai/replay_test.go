@@ -0,0 +1,96 @@
+package ai
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplayClient_Fake(t *testing.T) {
+	client, err := NewClient(&AIConfig{Type: "fake"}, AssistantConfig{})
+	require.NoError(t, err)
+
+	result, err := client.GeneratePrompt(context.Background(), testPrompt, nil)
+
+	require.NoError(t, err, "Did not expect an error")
+	assert.Equal(t, defaultFakeResponse, result)
+
+	client, err = NewClient(&AIConfig{Type: "fake", Response: "# Agenda\nNothing planned.\n"}, AssistantConfig{})
+	require.NoError(t, err)
+
+	var received []string
+
+	result, err = client.StreamPrompt(context.Background(), testPrompt, nil, func(chunk string) error {
+		received = append(received, chunk)
+		return nil
+	})
+
+	require.NoError(t, err, "Did not expect an error")
+	assert.Equal(t, "# Agenda\nNothing planned.\n", result)
+	assert.Equal(t, []string{"# Agenda\n", "Nothing planned.\n"}, received, "Response should be streamed line by line")
+}
+
+func TestReplayClient_Replay(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := NewClient(&AIConfig{Type: "replay"}, AssistantConfig{})
+	require.Error(t, err, "Replay needs a recordings directory")
+
+	client, err := NewClient(&AIConfig{Type: "replay", Recordings: dir}, AssistantConfig{})
+	require.NoError(t, err)
+
+	_, err = client.GeneratePrompt(context.Background(), testPrompt, nil)
+	require.ErrorContains(t, err, "no recorded response for prompt")
+
+	prompt, err := testPrompt(AssistantConfig{}, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, PromptHash(prompt)+".md"), []byte("Recorded."), 0o600))
+
+	result, err := client.GeneratePrompt(context.Background(), testPrompt, nil)
+
+	require.NoError(t, err, "Did not expect an error")
+	assert.Equal(t, "Recorded.", result)
+}
+
+func TestReplayClient_Record(t *testing.T) {
+	dir := t.TempDir()
+
+	server := newAnthropicStandIn(t, []string{"Good ", "morning."}, nil)
+
+	cc := &AIConfig{Type: "replay", Recordings: dir, Record: &AIConfig{
+		Type:    "anthropic",
+		APIKey:  "anthropic-key",
+		Model:   "claude-test",
+		BaseURL: server.URL,
+	}}
+
+	client, err := NewClient(cc, AssistantConfig{})
+	require.NoError(t, err)
+
+	conv, err := NewConversation(AssistantConfig{Name: "Spark"}, nil)
+	require.NoError(t, err)
+
+	conv.AddUser("Good morning!")
+
+	result, err := client.StreamChat(context.Background(), conv, func(string) error { return nil })
+
+	require.NoError(t, err, "Did not expect an error")
+	assert.Equal(t, "Good morning.", result)
+
+	server.Close()
+
+	cc.Record = nil
+
+	client, err = NewClient(cc, AssistantConfig{})
+	require.NoError(t, err)
+
+	result, err = client.StreamChat(context.Background(), conv, func(string) error { return nil })
+
+	require.NoError(t, err, "Recorded response should be replayed without the provider")
+	assert.Equal(t, "Good morning.", result)
+}
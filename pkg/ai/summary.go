@@ -0,0 +1,244 @@
+package ai
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+
+	"google.golang.org/genai"
+)
+
+// Schema is the subset of JSON schema that all providers support for
+// structured responses. All properties of an object are required.
+type Schema struct {
+	Type        string // JSON schema type, eg. "object", "array" or "string"
+	Description string
+	Properties  []SchemaProperty
+	Items       *Schema
+}
+
+type SchemaProperty struct {
+	Name   string
+	Schema *Schema
+}
+
+// JSONSchema returns the schema as a JSON schema object, strict enough for
+// OpenAI's structured outputs
+func (s *Schema) JSONSchema() map[string]any {
+	result := map[string]any{"type": s.Type}
+
+	if s.Description != "" {
+		result["description"] = s.Description
+	}
+
+	if s.Items != nil {
+		result["items"] = s.Items.JSONSchema()
+	}
+
+	if s.Type == "object" {
+		properties := map[string]any{}
+		required := make([]string, 0, len(s.Properties))
+
+		for _, p := range s.Properties {
+			properties[p.Name] = p.Schema.JSONSchema()
+			required = append(required, p.Name)
+		}
+
+		result["properties"] = properties
+		result["required"] = required
+		result["additionalProperties"] = false
+	}
+
+	return result
+}
+
+func (s *Schema) genai() *genai.Schema {
+	result := &genai.Schema{
+		Type:        genai.Type(strings.ToUpper(s.Type)),
+		Description: s.Description,
+	}
+
+	if s.Items != nil {
+		result.Items = s.Items.genai()
+	}
+
+	if s.Type == "object" {
+		result.Properties = map[string]*genai.Schema{}
+
+		for _, p := range s.Properties {
+			result.Properties[p.Name] = p.Schema.genai()
+			result.Required = append(result.Required, p.Name)
+			result.PropertyOrdering = append(result.PropertyOrdering, p.Name)
+		}
+	}
+
+	return result
+}
+
+// Summary is the structured response to a summary prompt
+type Summary struct {
+	Greeting  string         `json:"greeting"`
+	Schedule  []ScheduleItem `json:"schedule"`
+	Todos     []string       `json:"todos"`
+	Reminders []string       `json:"reminders"`
+	Weather   string         `json:"weather"`
+
+	// Provider and Model are those of the LLM that produced the summary; they
+	// are set by the caller, not by the LLM
+	Provider string `json:"provider,omitempty"`
+	Model    string `json:"model,omitempty"`
+}
+
+type ScheduleItem struct {
+	Day     string   `json:"day"`
+	Time    string   `json:"time"`
+	Title   string   `json:"title"`
+	Details string   `json:"details"`
+	Entries []uint64 `json:"entries"`
+}
+
+var SummarySchema = &Schema{
+	Type: "object",
+	Properties: []SchemaProperty{
+		{"greeting", &Schema{Type: "string", Description: "A greeting in the style of the persona"}},
+		{"schedule", &Schema{
+			Type:        "array",
+			Description: "The schedule, in chronological order",
+			Items: &Schema{
+				Type: "object",
+				Properties: []SchemaProperty{
+					{"day", &Schema{Type: "string", Description: "The day, eg. Monday, 2025-03-03"}},
+					{"time", &Schema{Type: "string", Description: "The time in 24 hour notation, or empty for the whole day"}},
+					{"title", &Schema{Type: "string", Description: "A short title"}},
+					{"details", &Schema{Type: "string", Description: "Details and remarks, or empty"}},
+					{"entries", &Schema{
+						Type:        "array",
+						Description: "The IDs of the entries this item is based on",
+						Items:       &Schema{Type: "integer"},
+					}},
+				},
+			},
+		}},
+		{"todos", &Schema{Type: "array", Description: "Things to do", Items: &Schema{Type: "string"}}},
+		{"reminders", &Schema{Type: "array", Description: "Things to remember", Items: &Schema{Type: "string"}}},
+		{"weather", &Schema{Type: "string", Description: "A remark about the weather, or empty"}},
+	},
+}
+
+// StructuredPrompt asks for a response that matches the schema instead of
+// Markdown
+func StructuredPrompt(p Prompt) Prompt {
+	return func(assistant AssistantConfig, data any) ([]string, error) {
+		prompt, err := p(assistant, data)
+		if err != nil {
+			return nil, err
+		}
+
+		return append(prompt,
+			"Ignore the instructions about Markdown: respond with JSON that matches the provided schema.",
+			"Reference the IDs of the entries each schedule item is based on.",
+		), nil
+	}
+}
+
+// ParseSummary parses and validates a structured response. Schedule items may
+// only reference the entries with the given IDs.
+func ParseSummary(response string, entryIDs []uint64) (*Summary, error) {
+	response = strings.TrimSpace(response)
+	response = strings.TrimPrefix(response, "```json")
+	response = strings.TrimSuffix(strings.TrimPrefix(response, "```"), "```")
+
+	dec := json.NewDecoder(strings.NewReader(response))
+	dec.DisallowUnknownFields()
+
+	var s Summary
+
+	if err := dec.Decode(&s); err != nil {
+		return nil, fmt.Errorf("invalid structured response: %w", err)
+	}
+
+	s.Provider, s.Model = "", ""
+
+	// Consumers of the JSON should not have to deal with null
+	s.Schedule = nonNil(s.Schedule)
+	s.Todos = nonNil(s.Todos)
+	s.Reminders = nonNil(s.Reminders)
+
+	if s.Greeting == "" {
+		return nil, errors.New("invalid structured response: no greeting")
+	}
+
+	for i, item := range s.Schedule {
+		s.Schedule[i].Entries = nonNil(item.Entries)
+
+		if item.Title == "" {
+			return nil, errors.New("invalid structured response: schedule item without title")
+		}
+
+		for _, id := range item.Entries {
+			if !slices.Contains(entryIDs, id) {
+				return nil, fmt.Errorf("invalid structured response: %q references unknown entry %d", item.Title, id)
+			}
+		}
+	}
+
+	return &s, nil
+}
+
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+
+	return s
+}
+
+// DefaultSummaryTemplate renders a structured summary as Markdown
+const DefaultSummaryTemplate = `{{ .Greeting }}
+{{ with .Weather }}
+{{ . }}
+{{ end }}
+{{- with .Schedule }}
+## Schedule
+{{ range . }}
+- **{{ .Day }}{{ with .Time }} {{ . }}{{ end }}**: {{ .Title }}{{ with .Details }} - {{ . }}{{ end }}
+{{- end }}
+{{ end }}
+{{- with .Todos }}
+## Todo's
+{{ range . }}
+- {{ . }}
+{{- end }}
+{{ end }}
+{{- with .Reminders }}
+## Reminders
+{{ range . }}
+- {{ . }}
+{{- end }}
+{{ end -}}
+`
+
+// Render renders the summary with a text/template; an empty template uses
+// DefaultSummaryTemplate
+func (s *Summary) Render(tmpl string) (string, error) {
+	if tmpl == "" {
+		tmpl = DefaultSummaryTemplate
+	}
+
+	t, err := template.New("summary").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return "", err
+	}
+
+	var b bytes.Buffer
+
+	if err := t.Execute(&b, s); err != nil {
+		return "", err
+	}
+
+	return b.String(), nil
+}
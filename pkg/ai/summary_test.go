@@ -0,0 +1,110 @@
+package ai
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSummary = `{
+	"greeting": "Good morning, sir.",
+	"schedule": [
+		{"day": "Monday, 2025-03-03", "time": "09:30", "title": "Dentist", "details": "Bring your card", "entries": [7]},
+		{"day": "Tuesday, 2025-03-04", "time": "", "title": "Bin day", "details": "", "entries": []}
+	],
+	"todos": ["Buy milk"],
+	"reminders": [],
+	"weather": "Rain is expected."
+}`
+
+func TestParseSummary(t *testing.T) {
+	s, err := ParseSummary("```json\n"+testSummary+"\n```", []uint64{7})
+
+	require.NoError(t, err, "Did not expect an error")
+	assert.Equal(t, "Good morning, sir.", s.Greeting)
+	require.Len(t, s.Schedule, 2)
+	assert.Equal(t, []uint64{7}, s.Schedule[0].Entries)
+	assert.Equal(t, []string{"Buy milk"}, s.Todos)
+	assert.NotNil(t, s.Reminders)
+}
+
+func TestParseSummary_Provider(t *testing.T) {
+	s, err := ParseSummary(`{"greeting": "Hello", "provider": "made-up", "model": "made-up"}`, nil)
+
+	require.NoError(t, err, "Did not expect an error")
+	assert.Empty(t, s.Provider, "Only the caller knows which LLM produced the summary")
+	assert.Empty(t, s.Model)
+
+	s.Provider, s.Model = "openai", "gpt-4o"
+
+	b, err := json.Marshal(s)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"provider":"openai","model":"gpt-4o"`)
+}
+
+func TestParseSummary_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		err      string
+	}{
+		{"not JSON", "Good morning, sir.", "invalid structured response"},
+		{"unknown field", `{"greeting": "Hello", "mood": "cheerful"}`, "unknown field"},
+		{"no greeting", `{"weather": "Sunny"}`, "no greeting"},
+		{"unknown entry", testSummary, "references unknown entry 7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseSummary(tt.response, nil)
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestSummary_Render(t *testing.T) {
+	s, err := ParseSummary(testSummary, []uint64{7})
+	require.NoError(t, err)
+
+	md, err := s.Render("")
+	require.NoError(t, err)
+
+	assert.Equal(t, `Good morning, sir.
+
+Rain is expected.
+
+## Schedule
+
+- **Monday, 2025-03-03 09:30**: Dentist - Bring your card
+- **Tuesday, 2025-03-04**: Bin day
+
+## Todo's
+
+- Buy milk
+`, md)
+
+	md, err = s.Render("{{ len .Schedule }} items")
+	require.NoError(t, err)
+	assert.Equal(t, "2 items", md)
+
+	_, err = s.Render("{{ .Mood }}")
+	assert.Error(t, err, "Unknown fields should fail")
+}
+
+func TestSchema_JSONSchema(t *testing.T) {
+	schema := SummarySchema.JSONSchema()
+
+	assert.Equal(t, false, schema["additionalProperties"], "Objects should be strict")
+	assert.Equal(t, []string{"greeting", "schedule", "todos", "reminders", "weather"}, schema["required"])
+
+	item := schema["properties"].(map[string]any)["schedule"].(map[string]any)["items"].(map[string]any)
+	assert.Equal(t, "object", item["type"])
+	assert.Contains(t, item["required"], "entries")
+
+	g := SummarySchema.genai()
+	assert.Equal(t, "OBJECT", string(g.Type))
+	assert.Equal(t, []string{"greeting", "schedule", "todos", "reminders", "weather"}, g.PropertyOrdering)
+	assert.Equal(t, "INTEGER", string(g.Properties["schedule"].Items.Properties["entries"].Items.Type))
+}
@@ -0,0 +1,110 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// maxToolRounds limits the number of consecutive rounds of tool calls the
+// model may make before it has to answer
+const maxToolRounds = 10
+
+var ErrTooManyToolCalls = errors.New("too many consecutive tool calls")
+
+// ToolParameter describes a single argument of a tool
+type ToolParameter struct {
+	Name        string
+	Type        string // JSON schema type, eg. "string" or "integer"
+	Description string
+	Required    bool
+}
+
+// ToolFunc runs a tool with the arguments provided by the model; the result is
+// sent back to the model as JSON
+type ToolFunc func(ctx context.Context, args ToolArgs) (any, error)
+
+// Tool is a function the model may call during a conversation
+type Tool struct {
+	Name        string
+	Description string
+	Parameters  []ToolParameter
+	Call        ToolFunc
+}
+
+type ToolArgs map[string]any
+
+func (a ToolArgs) String(key string) string {
+	switch v := a[key].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// JSONSchema returns the parameters of the tool as a JSON schema object
+func (t Tool) JSONSchema() map[string]any {
+	properties := map[string]any{}
+	required := []string{}
+
+	for _, p := range t.Parameters {
+		properties[p.Name] = map[string]any{
+			"type":        p.Type,
+			"description": p.Description,
+		}
+
+		if p.Required {
+			required = append(required, p.Name)
+		}
+	}
+
+	return map[string]any{
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+}
+
+type toolCallsKey struct{}
+
+// withToolCalls returns a context in which called is set once a tool runs
+func withToolCalls(ctx context.Context, called *bool) context.Context {
+	return context.WithValue(ctx, toolCallsKey{}, called)
+}
+
+// callTool runs the named tool and returns the result as JSON; errors are
+// reported to the model instead of aborting the conversation
+func (c *Conversation) callTool(ctx context.Context, name string, args map[string]any) string {
+	for _, t := range c.Tools {
+		if t.Name != name {
+			continue
+		}
+
+		if called, ok := ctx.Value(toolCallsKey{}).(*bool); ok {
+			*called = true
+		}
+
+		result, err := t.Call(ctx, args)
+		if err != nil {
+			return toolError(err)
+		}
+
+		j, err := json.Marshal(result)
+		if err != nil {
+			return toolError(err)
+		}
+
+		return string(j)
+	}
+
+	return toolError(fmt.Errorf("unknown tool: %s", name))
+}
+
+func toolError(err error) string {
+	j, _ := json.Marshal(map[string]string{"error": err.Error()})
+	return string(j)
+}
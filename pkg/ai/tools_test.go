@@ -0,0 +1,67 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testTool() Tool {
+	return Tool{
+		Name:        "echo",
+		Description: "Echo the input",
+		Parameters: []ToolParameter{
+			{Name: "text", Type: "string", Description: "The text", Required: true},
+			{Name: "times", Type: "integer", Description: "How often"},
+		},
+		Call: func(_ context.Context, args ToolArgs) (any, error) {
+			if args.String("text") == "fail" {
+				return nil, errors.New("failed on purpose")
+			}
+
+			return map[string]string{"echo": args.String("text")}, nil
+		},
+	}
+}
+
+func TestTool_JSONSchema(t *testing.T) {
+	assert.Equal(t, map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"text":  map[string]any{"type": "string", "description": "The text"},
+			"times": map[string]any{"type": "integer", "description": "How often"},
+		},
+		"required": []string{"text"},
+	}, testTool().JSONSchema())
+}
+
+func TestToolArgs_String(t *testing.T) {
+	args := ToolArgs{"s": "value", "n": float64(3)}
+
+	assert.Equal(t, "value", args.String("s"))
+	assert.Equal(t, "3", args.String("n"), "Non-string values should be formatted")
+	assert.Empty(t, args.String("missing"))
+}
+
+func TestConversation_callTool(t *testing.T) {
+	conv := &Conversation{Tools: []Tool{testTool()}}
+
+	tests := []struct {
+		name     string
+		tool     string
+		args     map[string]any
+		expected string
+	}{
+		{"Successful call", "echo", map[string]any{"text": "hello"}, `{"echo":"hello"}`},
+		{"Failing call", "echo", map[string]any{"text": "fail"}, `{"error":"failed on purpose"}`},
+		{"Unknown tool", "unknown", nil, `{"error":"unknown tool: unknown"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.JSONEq(t, tt.expected, conv.callTool(context.Background(), tt.tool, tt.args))
+		})
+	}
+}
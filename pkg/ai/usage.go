@@ -0,0 +1,36 @@
+package ai
+
+import (
+	"context"
+)
+
+// Usage is the number of tokens used by a single call to a provider
+type Usage struct {
+	Provider         string
+	Model            string
+	PromptTokens     int
+	CompletionTokens int
+}
+
+// UsageFunc is called with the usage of every call to a provider
+type UsageFunc func(Usage)
+
+type usageKey struct{}
+
+// WithUsageFunc returns a context that reports the usage of every call made
+// with it to fn
+func WithUsageFunc(ctx context.Context, fn UsageFunc) context.Context {
+	return context.WithValue(ctx, usageKey{}, fn)
+}
+
+// ReportUsage passes the usage of a call to the UsageFunc of the context, if
+// there is one; calls without token counts are not reported
+func ReportUsage(ctx context.Context, u Usage) {
+	if u.PromptTokens == 0 && u.CompletionTokens == 0 {
+		return
+	}
+
+	if fn, ok := ctx.Value(usageKey{}).(UsageFunc); ok {
+		fn(u)
+	}
+}
@@ -2,7 +2,10 @@ package app
 
 import (
 	"log/slog"
+	"time"
 
+	"github.com/jovandeginste/spark-personal-assistant/pkg/ai"
+	"github.com/jovandeginste/spark-personal-assistant/pkg/data"
 	"gorm.io/gorm"
 )
 
@@ -10,16 +13,30 @@ type App struct {
 	ConfigFile string
 	Config     Config
 
+	// AsOf runs Spark as if today is this day, formatted as YYYY-MM-DD
+	AsOf string
+
 	db     *gorm.DB
 	logger slog.Logger
+	clock  data.Clock
 }
 
 func NewApp() *App {
-	a := &App{}
+	a := &App{clock: data.SystemClock{}}
 
 	return a
 }
 
+// Now returns the current time of the clock of the app
+func (a *App) Now() time.Time {
+	return a.clock.Now()
+}
+
+// Today returns midnight of the current day of the clock of the app
+func (a *App) Today() time.Time {
+	return data.TodayOf(a.clock)
+}
+
 func (a *App) Logger() *slog.Logger {
 	return &a.logger
 }
@@ -29,6 +46,10 @@ func (a *App) Initialize() error {
 		return err
 	}
 
+	if err := a.initializeClock(); err != nil {
+		return err
+	}
+
 	a.Config.Mailer.app = a
 
 	a.initializeLogger()
@@ -40,6 +61,57 @@ func (a *App) Initialize() error {
 	return nil
 }
 
+// initializeClock moves the clock to the day of AsOf, if set
+func (a *App) initializeClock() error {
+	if a.AsOf == "" {
+		return nil
+	}
+
+	clock, err := data.AsOf(a.AsOf)
+	if err != nil {
+		return err
+	}
+
+	a.clock = clock
+
+	return nil
+}
+
 func (a *App) initializeLogger() {
 	a.logger = *slog.Default()
 }
+
+// AIClient returns a client for the configured LLM, which fails over to the
+// fallback LLMs in order, with the preferences of the persona
+func (a *App) AIClient() (*ai.Chain, error) {
+	return a.AIClientFor(a.Assistant())
+}
+
+// Assistant returns the configured persona, on the clock of the app
+func (a *App) Assistant() ai.AssistantConfig {
+	assistant := a.Config.Assistant
+	assistant.Clock = a.clock
+
+	return assistant
+}
+
+// AIClientFor is AIClient for another version of the persona, like the one
+// for a recipient
+func (a *App) AIClientFor(assistant ai.AssistantConfig) (*ai.Chain, error) {
+	var configs []*ai.AIConfig
+
+	if a.Config.LLM != nil {
+		configs = append(configs, a.Config.LLM)
+	}
+
+	configs = append(configs, a.Config.LLMFallbacks...)
+
+	assistant.Clock = a.clock
+
+	configs, err := assistant.Configure(configs)
+	if err != nil {
+		return nil, err
+	}
+
+	return ai.NewChain(configs, assistant, a.Logger())
+}
@@ -5,9 +5,12 @@ import (
 	"log/slog"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/awterman/monkey"
 	"github.com/glebarez/sqlite"
+	"github.com/jovandeginste/spark-personal-assistant/pkg/ai"
+	"github.com/jovandeginste/spark-personal-assistant/pkg/data"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"gorm.io/gorm"
@@ -314,3 +317,19 @@ func TestApp_initializeDatabase(t *testing.T) {
 		})
 	}
 }
+
+func TestApp_AIClient_Clock(t *testing.T) {
+	a := newTestApp(t)
+	a.Config.LLM = &ai.AIConfig{Type: "fake"}
+	a.clock = data.FixedClock(time.Date(2031, time.July, 14, 8, 0, 0, 0, data.LocalTimezone))
+
+	aiClient, err := a.AIClient()
+	require.NoError(t, err)
+
+	p, err := a.Config.Formats.PromptFor("today")
+	require.NoError(t, err)
+
+	prompt, err := p(aiClient.Assistant(), nil)
+	require.NoError(t, err)
+	assert.Contains(t, prompt, "Today is: Monday, 2031-07-14", "Prompts should be rendered on the clock of the app")
+}
@@ -0,0 +1,118 @@
+package app
+
+import (
+	"time"
+
+	"github.com/jovandeginste/spark-personal-assistant/pkg/ai"
+	"github.com/jovandeginste/spark-personal-assistant/pkg/data"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// defaultCacheTTL is how long a response is reused when no TTL is configured
+const defaultCacheTTL = 12 * time.Hour
+
+type CacheConfig struct {
+	TTL      time.Duration `mapstructure:"ttl"`
+	Disabled bool          `mapstructure:"disabled"`
+}
+
+func (cc CacheConfig) ttl() time.Duration {
+	if cc.TTL > 0 {
+		return cc.TTL
+	}
+
+	return defaultCacheTTL
+}
+
+func (a *App) expiry() time.Time {
+	return a.Now().Add(-a.Config.Cache.ttl())
+}
+
+// CacheHash returns the key of the response to the prompt, for the LLM that
+// handles it and the persona
+func (a *App) CacheHash(llm *ai.AIConfig, prompt []string) string {
+	return data.CacheHash(llm.Type, llm.Model, a.Config.Assistant.Name, prompt)
+}
+
+// CachedResponse returns the response for the hash, unless it has expired
+func (a *App) CachedResponse(hash string) (*data.CachedResponse, error) {
+	var rs []data.CachedResponse
+
+	if err := a.db.Where("hash = ?", hash).Where("created_at >= ?", a.expiry()).Limit(1).Find(&rs).Error; err != nil {
+		return nil, err
+	}
+
+	if len(rs) == 0 {
+		return nil, nil
+	}
+
+	r := rs[0]
+
+	if err := a.db.Model(&r).UpdateColumn("hits", gorm.Expr("hits + 1")).Error; err != nil {
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+// CacheResponse stores the response for the hash, replacing an older response,
+// along with the LLM that produced it
+func (a *App) CacheResponse(hash, response string, producer *ai.AIConfig) error {
+	r := data.CachedResponse{
+		Hash:      hash,
+		Provider:  producer.Type,
+		Model:     producer.Model,
+		Persona:   a.Config.Assistant.Name,
+		Response:  response,
+		Misses:    1,
+		CreatedAt: a.Now(),
+	}
+
+	return a.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "hash"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"provider":   r.Provider,
+			"model":      r.Model,
+			"response":   r.Response,
+			"created_at": r.CreatedAt,
+			"misses":     gorm.Expr("misses + 1"),
+		}),
+	}).Create(&r).Error
+}
+
+func (a *App) CacheStats() (data.CacheStats, error) {
+	var s data.CacheStats
+
+	if err := a.db.Model(&data.CachedResponse{}).Count(&s.Responses).Error; err != nil {
+		return s, err
+	}
+
+	if err := a.db.Model(&data.CachedResponse{}).Where("created_at < ?", a.expiry()).Count(&s.Expired).Error; err != nil {
+		return s, err
+	}
+
+	var totals struct{ Hits, Misses uint64 }
+
+	if err := a.db.Model(&data.CachedResponse{}).
+		Select("COALESCE(SUM(hits), 0) AS hits, COALESCE(SUM(misses), 0) AS misses").
+		Scan(&totals).Error; err != nil {
+		return s, err
+	}
+
+	s.Hits, s.Misses = totals.Hits, totals.Misses
+
+	return s, nil
+}
+
+// PurgeCache deletes all cached responses, or only the expired ones
+func (a *App) PurgeCache(expiredOnly bool) (int64, error) {
+	q := a.db.Session(&gorm.Session{AllowGlobalUpdate: true})
+	if expiredOnly {
+		q = q.Where("created_at < ?", a.expiry())
+	}
+
+	res := q.Delete(&data.CachedResponse{})
+
+	return res.RowsAffected, res.Error
+}
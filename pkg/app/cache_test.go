@@ -0,0 +1,76 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jovandeginste/spark-personal-assistant/pkg/ai"
+	"github.com/jovandeginste/spark-personal-assistant/pkg/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApp_Cache(t *testing.T) {
+	a := newTestApp(t)
+	a.Config.LLM = &ai.AIConfig{Type: "gemini", Model: "gemini-test"}
+	a.Config.Assistant.Name = "Spark"
+
+	hash := a.CacheHash(a.Config.LLM, []string{"Show the agenda"})
+
+	r, err := a.CachedResponse(hash)
+	require.NoError(t, err)
+	assert.Nil(t, r, "Empty cache should miss")
+
+	require.NoError(t, a.CacheResponse(hash, "Nothing planned, sir.", a.Config.LLM))
+
+	r, err = a.CachedResponse(hash)
+	require.NoError(t, err)
+	require.NotNil(t, r, "Cached response should hit")
+	assert.Equal(t, "Nothing planned, sir.", r.Response)
+	assert.Equal(t, "gemini-test", r.Model)
+
+	a.Config.LLM.Model = "gemini-other"
+	assert.NotEqual(t, hash, a.CacheHash(a.Config.LLM, []string{"Show the agenda"}), "Model should be part of the key")
+
+	require.NoError(t, a.CacheResponse(hash, "Still nothing, sir.", a.Config.LLM))
+
+	stats, err := a.CacheStats()
+	require.NoError(t, err)
+	assert.Equal(t, data.CacheStats{Responses: 1, Hits: 1, Misses: 2}, stats)
+
+	a.Config.Cache.TTL = time.Nanosecond
+	time.Sleep(time.Millisecond)
+
+	r, err = a.CachedResponse(hash)
+	require.NoError(t, err)
+	assert.Nil(t, r, "Expired response should miss")
+
+	n, err := a.PurgeCache(true)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	stats, err = a.CacheStats()
+	require.NoError(t, err)
+	assert.Equal(t, data.CacheStats{}, stats, "Purge should clear the statistics")
+}
+
+func TestApp_CacheHash_Persona(t *testing.T) {
+	a := newTestApp(t)
+	a.Config.LLM = &ai.AIConfig{Type: "ollama", Model: "llama3"}
+	a.Config.LLMFallbacks = []*ai.AIConfig{{Type: "openai", APIKey: "key", Model: "gpt-4o"}}
+
+	prompt := []string{"Show the agenda"}
+
+	aiClient, err := a.AIClient()
+	require.NoError(t, err)
+	assert.Equal(t, a.CacheHash(a.Config.LLM, prompt), a.CacheHash(aiClient.Primary(), prompt))
+
+	a.Config.Assistant.Provider = "openai"
+	a.Config.Assistant.Model = "gpt-4o-mini"
+
+	aiClient, err = a.AIClient()
+	require.NoError(t, err)
+	assert.Equal(t, "gpt-4o-mini", aiClient.Primary().Model, "Persona should pick the provider and model")
+	assert.NotEqual(t, a.CacheHash(a.Config.LLM, prompt), a.CacheHash(aiClient.Primary(), prompt),
+		"Key should follow the LLM of the persona")
+}
@@ -1,27 +1,52 @@
 package app
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/adrg/frontmatter"
 	"github.com/jovandeginste/spark-personal-assistant/pkg/ai"
+	"github.com/jovandeginste/spark-personal-assistant/pkg/data"
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	AssistantFile string         `mapstructure:"assistant"`
-	Database      DatabaseConfig `mapstructure:"database"`
-	UserData      UserData       `mapstructure:"user_data"`
-	ExtraContext  []string       `mapstructure:"extra_context"`
-	Mailer        Mailer         `mapstructure:"mail"`
-	LLM           *ai.AIConfig   `mapstructure:"llm"`
+	AssistantFile string          `mapstructure:"assistant"`
+	PersonasDir   string          `mapstructure:"personas"`
+	Database      DatabaseConfig  `mapstructure:"database"`
+	UserData      UserData        `mapstructure:"user_data"`
+	ExtraContext  []string        `mapstructure:"extra_context"`
+	Mailer        Mailer          `mapstructure:"mail"`
+	LLM           *ai.AIConfig    `mapstructure:"llm"`
+	LLMFallbacks  []*ai.AIConfig  `mapstructure:"llm_fallbacks"`
+	Formats       ai.Formats      `mapstructure:"formats"`
+	Cache         CacheConfig     `mapstructure:"cache"`
+	Locale        data.Locale     `mapstructure:"locale"`
+	Recipients    []Recipient     `mapstructure:"recipients"`
+	Prices        []Price         `mapstructure:"prices"`
+	Retrieval     RetrievalConfig `mapstructure:"retrieval"`
+	TagRules      []TagRule       `mapstructure:"tag_rules"`
+
+	// SummaryTemplateFile is a text/template that renders structured
+	// summaries as Markdown
+	SummaryTemplateFile string `mapstructure:"summary_template"`
+	SummaryTemplate     string `mapstructure:"-"`
 
 	AssistantFileCLI string             `mapstructure:"-"`
+	RecipientCLI     string             `mapstructure:"-"`
 	Assistant        ai.AssistantConfig `mapstructure:"-"`
 }
 
+// Recipient overrides the locale for a mail address
+type Recipient struct {
+	Address string `mapstructure:"address"`
+
+	data.Locale `mapstructure:",squash"`
+}
+
 type UserData struct {
 	Names []string `mapstructure:"names"`
 }
@@ -47,8 +72,28 @@ func (a *App) ReadConfig() error {
 		return err
 	}
 
+	if err := a.loadFormats(); err != nil {
+		return err
+	}
+
+	if err := a.setRecordingsPaths(); err != nil {
+		return err
+	}
+
+	if err := a.loadSummaryTemplate(); err != nil {
+		return err
+	}
+
+	if err := a.loadTagRules(); err != nil {
+		return err
+	}
+
 	a.SetDefaults()
 
+	if err := a.applyLocale(); err != nil {
+		return err
+	}
+
 	a.Config.Database.originalFile = a.Config.Database.File
 
 	return a.setDatabasePath()
@@ -59,24 +104,185 @@ func (a *App) configureAssistant() error {
 		return nil
 	}
 
-	input, err := os.Open(a.Config.AssistantFile)
+	assistant, err := readPersona(a.Config.AssistantFile)
 	if err != nil {
 		return err
 	}
 
-	rest, err := frontmatter.Parse(input, &a.Config.Assistant)
+	a.Config.Assistant = assistant
+
+	return nil
+}
+
+// readPersona reads a persona from the frontmatter and Markdown body of file
+func readPersona(file string) (ai.AssistantConfig, error) {
+	var assistant ai.AssistantConfig
+
+	input, err := os.Open(file)
+	if err != nil {
+		return assistant, err
+	}
+	defer input.Close()
+
+	rest, err := frontmatter.Parse(input, &assistant)
 	if err != nil {
+		return assistant, err
+	}
+
+	assistant.Style = string(rest)
+
+	return assistant, nil
+}
+
+// loadFormats reads the instructions of the user-defined formats from their
+// template files, relative to the config file
+func (a *App) loadFormats() error {
+	for name, f := range a.Config.Formats {
+		if f.File == "" {
+			continue
+		}
+
+		path, err := a.relativeToConfig(f.File)
+		if err != nil {
+			return err
+		}
+
+		f.File = path
+
+		if err := f.Load(); err != nil {
+			return err
+		}
+
+		a.Config.Formats[name] = f
+	}
+
+	return nil
+}
+
+// setRecordingsPaths makes the recordings directories of the replay and fake
+// providers relative to the config file
+func (a *App) setRecordingsPaths() error {
+	configs := append([]*ai.AIConfig{a.Config.LLM}, a.Config.LLMFallbacks...)
+
+	for _, cc := range configs {
+		if cc == nil || cc.Recordings == "" {
+			continue
+		}
+
+		path, err := a.relativeToConfig(cc.Recordings)
+		if err != nil {
+			return err
+		}
+
+		cc.Recordings = path
+	}
+
+	return nil
+}
+
+// applyLocale sets the locale of the assistant and of local date formatting,
+// with the language of the persona and the overrides of the recipient if one
+// is selected
+func (a *App) applyLocale() error {
+	l := a.Config.Locale.Merge(data.Locale{Language: a.Config.Assistant.Language})
+
+	if address := a.Config.RecipientCLI; address != "" {
+		r, ok := a.recipient(address)
+		if !ok {
+			return fmt.Errorf("unknown recipient: %s", address)
+		}
+
+		l = l.Merge(r.Locale)
+	}
+
+	if err := l.Validate(); err != nil {
 		return err
 	}
 
-	a.Config.Assistant.Style = string(rest)
+	a.Config.Assistant.Locale = l
+	data.LocalLocale = l.WithDefaults()
 
 	return nil
 }
 
+// recipient returns the recipient with the address, if it is configured
+func (a *App) recipient(address string) (Recipient, bool) {
+	i := slices.IndexFunc(a.Config.Recipients, func(r Recipient) bool {
+		return strings.EqualFold(r.Address, address)
+	})
+	if i < 0 {
+		return Recipient{}, false
+	}
+
+	return a.Config.Recipients[i], true
+}
+
+// RecipientLocale returns the locale of mails to the address: the configured
+// locale with the overrides of the recipient, if it is configured
+func (a *App) RecipientLocale(address string) data.Locale {
+	l := a.Config.Locale.Merge(data.Locale{Language: a.Config.Assistant.Language})
+
+	if r, ok := a.recipient(address); ok {
+		l = l.Merge(r.Locale)
+	}
+
+	return l.WithDefaults()
+}
+
+// RecipientAssistant returns the persona in the locale of the recipient, to
+// write a summary for them
+func (a *App) RecipientAssistant(address string) ai.AssistantConfig {
+	assistant := a.Assistant()
+	assistant.Locale = a.RecipientLocale(address)
+
+	return assistant
+}
+
+func (a *App) loadSummaryTemplate() error {
+	if a.Config.SummaryTemplateFile == "" {
+		return nil
+	}
+
+	path, err := a.relativeToConfig(a.Config.SummaryTemplateFile)
+	if err != nil {
+		return err
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	a.Config.SummaryTemplate = string(b)
+
+	return nil
+}
+
+func (a *App) relativeToConfig(path string) (string, error) {
+	if filepath.IsAbs(path) {
+		return path, nil
+	}
+
+	absPath, err := filepath.Abs(a.ConfigFile)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(filepath.Dir(absPath), filepath.Clean(path)), nil
+}
+
 func (a *App) setAssistantStylePath() error {
 	if a.Config.AssistantFileCLI != "" {
 		a.Config.AssistantFile = a.Config.AssistantFileCLI
+
+		if isPersonaName(a.Config.AssistantFile) {
+			file, err := a.personaFile(a.Config.AssistantFile)
+			if err != nil {
+				return err
+			}
+
+			a.Config.AssistantFile = file
+		}
 	}
 
 	if a.Config.AssistantFile == "" || strings.HasPrefix(a.Config.AssistantFile, "/") {
@@ -9,6 +9,7 @@ import (
 
 	"github.com/awterman/monkey"
 	"github.com/jovandeginste/spark-personal-assistant/pkg/ai"
+	"github.com/jovandeginste/spark-personal-assistant/pkg/data"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -468,3 +469,57 @@ func testEqualApp(t *testing.T, tempApp, app *App) {
 
 	// Assert the final database file path resolution specifically
 }
+
+func TestApp_loadFormats(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "weekend.tmpl"), []byte("Only the weekend"), 0o600))
+
+	app := &App{ConfigFile: filepath.Join(dir, "spark.yaml")}
+	app.Config.Formats = ai.Formats{
+		"weekend":  {File: "weekend.tmpl"},
+		"tomorrow": {Instructions: "Only tomorrow"},
+	}
+
+	require.NoError(t, app.loadFormats())
+
+	assert.Equal(t, "Only the weekend", app.Config.Formats["weekend"].Instructions, "Template file should be read relative to the config file")
+	assert.Equal(t, filepath.Join(dir, "weekend.tmpl"), app.Config.Formats["weekend"].File)
+	assert.Equal(t, "Only tomorrow", app.Config.Formats["tomorrow"].Instructions, "Inline instructions should be kept")
+
+	app.Config.Formats = ai.Formats{"missing": {File: "missing.tmpl"}}
+	assert.Error(t, app.loadFormats(), "Missing template file should return an error")
+}
+
+func TestApp_applyLocale(t *testing.T) {
+	defer func() { data.LocalLocale = data.DefaultLocale }()
+
+	app := &App{}
+	app.Config.Locale = data.Locale{Language: "Dutch"}
+	app.Config.Recipients = []Recipient{
+		{Address: "grandma@example.com", Locale: data.Locale{Language: "English", Units: "imperial", Clock: "12h"}},
+	}
+
+	require.NoError(t, app.applyLocale())
+	assert.Equal(t, data.Locale{Language: "Dutch"}, app.Config.Assistant.Locale)
+	assert.Equal(t, "24h", data.LocalLocale.Clock, "Defaults should be used for local formatting")
+
+	app.Config.RecipientCLI = "Grandma@example.com"
+
+	require.NoError(t, app.applyLocale())
+	assert.Equal(t, data.Locale{Language: "English", Units: "imperial", Clock: "12h"}, app.Config.Assistant.Locale, "Recipient should override the locale")
+	assert.Equal(t, "12h", data.LocalLocale.Clock)
+
+	app.Config.RecipientCLI = ""
+	app.Config.Assistant.Language = "French"
+
+	require.NoError(t, app.applyLocale())
+	assert.Equal(t, "French", app.Config.Assistant.Locale.Language, "Persona should override the language")
+
+	app.Config.Assistant.Language = ""
+	app.Config.RecipientCLI = "nobody@example.com"
+	assert.ErrorContains(t, app.applyLocale(), "unknown recipient")
+
+	app.Config.RecipientCLI = ""
+	app.Config.Locale.Clock = "25h"
+	assert.ErrorContains(t, app.applyLocale(), "invalid clock")
+}
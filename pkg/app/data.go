@@ -2,38 +2,61 @@ package app
 
 import (
 	"errors"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/jovandeginste/spark-personal-assistant/pkg/data"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// weatherPrefix is how the weather helper starts the summary of its entries
+const weatherPrefix = "Weather for "
+
 type EntryFilter struct {
-	Source    *data.Source
-	DaysBack  uint
-	DaysAhead uint
+	Source      *data.Source
+	DaysBack    uint
+	DaysAhead   uint
+	Tags        []string // only entries with any of these tags
+	ExcludeTags []string // no entries with any of these tags
 }
 
-func (ef *EntryFilter) From() time.Time {
-	return time.Now().Add(time.Duration(-ef.DaysBack*24) * time.Hour).Truncate(24 * time.Hour)
+func (ef *EntryFilter) From(now time.Time) time.Time {
+	return now.Add(time.Duration(-ef.DaysBack*24) * time.Hour).Truncate(24 * time.Hour)
 }
 
-func (ef *EntryFilter) To() time.Time {
-	return time.Now().Add(time.Duration(ef.DaysAhead*24) * time.Hour).Truncate(24 * time.Hour)
+func (ef *EntryFilter) To(now time.Time) time.Time {
+	return now.Add(time.Duration(ef.DaysAhead*24) * time.Hour).Truncate(24 * time.Hour)
 }
 
-func (ef *EntryFilter) Query(q *gorm.DB) *gorm.DB {
-	q = q.Where("date >= ?", ef.From()).Where("date <= ?", ef.To())
+// Query selects the entries that overlap with the period of the filter, so
+// entries that started before it but have not ended yet are included
+func (ef *EntryFilter) Query(q *gorm.DB, now time.Time) *gorm.DB {
+	return ef.Select(overlapping(q, ef.From(now), ef.To(now)))
+}
 
+// Select selects the entries of the source and tags of the filter, regardless
+// of its period
+func (ef *EntryFilter) Select(q *gorm.DB) *gorm.DB {
 	if ef.Source != nil {
 		q = q.Where("source_id = ?", ef.Source.ID)
 	}
 
+	if tags := data.NormalizeTags(ef.Tags); len(tags) > 0 {
+		q = q.Where("id IN (SELECT entry_id FROM entry_tags WHERE tag_name IN ?)", tags)
+	}
+
+	if tags := data.NormalizeTags(ef.ExcludeTags); len(tags) > 0 {
+		q = q.Where("id NOT IN (SELECT entry_id FROM entry_tags WHERE tag_name IN ?)", tags)
+	}
+
 	return q
 }
 
 func (a *App) CurrentEntries(ef EntryFilter) (data.Entries, error) {
-	q := ef.Query(a.DB())
+	now := a.Now()
+	q := ef.Query(a.DB(), now)
 
 	var entries data.Entries
 
@@ -41,7 +64,46 @@ func (a *App) CurrentEntries(ef EntryFilter) (data.Entries, error) {
 		return nil, err
 	}
 
-	return entries, nil
+	return entries.Expand(ef.From(now), ef.To(now)), nil
+}
+
+// overlapping selects the entries that start or are ongoing between from and
+// to, inclusive, and the recurring entries that started before to; expand
+// their occurrences with data.Entries.Expand
+func overlapping(q *gorm.DB, from, to time.Time) *gorm.DB {
+	return q.Where("date <= ?", to).Where("date >= ? OR ends_at > ? OR repeat <> ''", from, from)
+}
+
+// EntriesBetween returns all entries between from and to, inclusive,
+// including the entries that started before from but have not ended yet
+func (a *App) EntriesBetween(from, to time.Time) (data.Entries, error) {
+	var entries data.Entries
+
+	if err := overlapping(a.DB(), from, to).
+		Order("date ASC").
+		Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	return entries.Expand(from, to), nil
+}
+
+// WeatherForDay returns the weather forecasts for the given day
+func (a *App) WeatherForDay(day time.Time) (data.Entries, error) {
+	entries, err := a.EntriesBetween(day, day.AddDate(0, 0, 1).Add(-time.Second))
+	if err != nil {
+		return nil, err
+	}
+
+	var weather data.Entries
+
+	for _, e := range entries {
+		if strings.HasPrefix(e.Summary, weatherPrefix) {
+			weather = append(weather, e)
+		}
+	}
+
+	return weather, nil
 }
 
 func (a *App) Entries() (data.Entries, error) {
@@ -57,8 +119,48 @@ func (a *App) Entries() (data.Entries, error) {
 	return entries, nil
 }
 
+// RecurringEntries returns the entries that repeat
+func (a *App) RecurringEntries() (data.Entries, error) {
+	var entries data.Entries
+
+	if err := a.DB().Where("repeat <> ''").Order("date ASC").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// UpdateEntry saves the changes to an entry, without its source. It marks
+// the entry as edited, so the next import of its source keeps the changes,
+// and as moved if it got another source, so the imports of that source keep
+// it.
+func (a *App) UpdateEntry(e *data.Entry) error {
+	now := a.Now()
+	e.EditedAt = &now
+
+	var sourceID uint64
+
+	if err := a.db.Model(&data.Entry{}).Select("source_id").Where("id = ?", e.ID).Scan(&sourceID).Error; err != nil {
+		return err
+	}
+
+	if sourceID != 0 && sourceID != e.SourceID {
+		e.Moved = true
+	}
+
+	if err := a.db.Omit(clause.Associations).Save(e).Error; err != nil {
+		return err
+	}
+
+	return a.db.Model(e).Association("Tags").Replace(e.Tags)
+}
+
 func (a *App) DeleteEntry(e *data.Entry) error {
-	return a.DB().Delete(&e).Error
+	if err := a.DB().Delete(&e).Error; err != nil {
+		return err
+	}
+
+	return a.deleteOrphanTags()
 }
 
 func (a *App) FindEntry(e *data.Entry) error {
@@ -66,19 +168,30 @@ func (a *App) FindEntry(e *data.Entry) error {
 }
 
 func (a *App) FindEntryByRemoteID(sourceID uint64, e *data.Entry) (uint64, error) {
+	entry, err := a.findImportedEntry(sourceID, e)
+	if err != nil || entry == nil {
+		return 0, err
+	}
+
+	return entry.ID, nil
+}
+
+// findImportedEntry returns the stored entry of the source that has the
+// remote ID of e, or nil
+func (a *App) findImportedEntry(sourceID uint64, e *data.Entry) (*data.Entry, error) {
 	rid := e.NewRemoteID()
 
 	var entry data.Entry
 
-	if err := a.DB().Where("source_id = ?", sourceID).Where("remote_id = ?", rid).First(&entry).Error; err != nil {
+	if err := a.db.Preload("Tags").Where("source_id = ?", sourceID).Where("remote_id = ?", rid).First(&entry).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return 0, nil
+			return nil, nil
 		}
 
-		return 0, err
+		return nil, err
 	}
 
-	return entry.ID, nil
+	return &entry, nil
 }
 
 func (a *App) Sources() (data.Sources, error) {
@@ -97,7 +210,11 @@ func (a *App) CreateEntry(entry *data.Entry) error {
 }
 
 func (a *App) DeleteSource(s *data.Source) error {
-	return a.DB().Select("Entries").Delete(&s).Error
+	if err := a.DB().Select("Entries").Delete(&s).Error; err != nil {
+		return err
+	}
+
+	return a.deleteOrphanTags()
 }
 
 func (a *App) FindSourceByName(name string) (*data.Source, error) {
@@ -115,18 +232,26 @@ func (a *App) CreateSource(src *data.Source) error {
 	return a.DB().Create(src).Error
 }
 
+// FetchExistingEntries gives the imported entries the ID of the stored entry
+// they replace; entries that were edited by hand are kept as they are
 func (a *App) FetchExistingEntries(sourceID uint64, entries data.Entries) {
 	for i, e := range entries {
-		id, err := a.FindEntryByRemoteID(sourceID, &e)
+		existing, err := a.findImportedEntry(sourceID, &e)
 		if err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				continue
-			}
-
 			a.logger.Error(err.Error())
+			continue
+		}
+
+		if existing == nil {
+			continue
+		}
+
+		if existing.EditedAt != nil {
+			entries[i] = *existing
+			continue
 		}
 
-		entries[i].ID = id
+		entries[i].ID = existing.ID
 	}
 }
 
@@ -137,9 +262,65 @@ func (a *App) ReplaceSourceEntries(src *data.Source, entries data.Entries) error
 		entries[i].SourceID = src.ID
 	}
 
-	if err := a.DB().Model(&data.Entry{}).Save(entries).Error; err != nil {
+	a.tagEntries(src, entries)
+
+	kept, err := a.editedEntries(src.ID, entries)
+	if err != nil {
+		return err
+	}
+
+	// The tags are saved again with the entries, as they are now
+	if err := a.db.Exec("DELETE FROM entry_tags WHERE entry_id IN (SELECT id FROM entries WHERE source_id = ?)", src.ID).Error; err != nil {
 		return err
 	}
 
-	return a.DB().Model(&src).Association("Entries").Unscoped().Replace(entries)
+	for _, es := range []data.Entries{entries, kept} {
+		if len(es) == 0 {
+			continue
+		}
+
+		if err := a.DB().Model(&data.Entry{}).Save(es).Error; err != nil {
+			return err
+		}
+	}
+
+	if err := a.DB().Model(&src).Association("Entries").Unscoped().Replace(slices.Concat(entries, kept)); err != nil {
+		return err
+	}
+
+	return a.deleteOrphanTags()
+}
+
+// editedEntries returns the entries that were moved to the source, which are
+// not among the imported entries. An import keeps them. Other edited entries
+// that are not imported anymore were removed from the source; they are
+// dropped with a warning.
+func (a *App) editedEntries(sourceID uint64, imported data.Entries) (data.Entries, error) {
+	q := a.DB().Where("source_id = ? AND edited_at IS NOT NULL", sourceID)
+
+	var ids []uint64
+
+	for _, e := range imported {
+		if e.ID != 0 {
+			ids = append(ids, e.ID)
+		}
+	}
+
+	if len(ids) > 0 {
+		q = q.Where("id NOT IN ?", ids)
+	}
+
+	var entries data.Entries
+
+	if err := q.Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	return slices.DeleteFunc(entries, func(e data.Entry) bool {
+		if !e.Moved {
+			a.Logger().Warn("Dropping edited entry that was removed from its source", "id", e.ID, "summary", e.Summary)
+		}
+
+		return !e.Moved
+	}), nil
 }
@@ -0,0 +1,224 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jovandeginste/spark-personal-assistant/pkg/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApp_CurrentEntries_Overlap(t *testing.T) {
+	a := newTestApp(t)
+
+	a.clock = data.FixedClock(time.Date(2025, time.July, 10, 12, 0, 0, 0, time.UTC))
+
+	src, err := a.FindSourceByName("manual")
+	require.NoError(t, err)
+
+	day := func(d int) time.Time { return time.Date(2025, time.July, d, 0, 0, 0, 0, time.UTC) }
+
+	for _, e := range []data.Entry{
+		{Summary: "Holiday", Date: data.HumanTime{Time: day(1)}, End: &data.HumanTime{Time: day(15)}, AllDay: true},
+		{Summary: "Conference", Date: data.HumanTime{Time: day(1)}, End: &data.HumanTime{Time: day(4)}, AllDay: true},
+		{Summary: "Dentist", Date: data.HumanTime{Time: day(9).Add(10 * time.Hour)}},
+		{Summary: "Old dentist", Date: data.HumanTime{Time: day(2).Add(10 * time.Hour)}},
+	} {
+		e.Source = src
+		require.NoError(t, a.CreateEntry(&e))
+	}
+
+	entries, err := a.CurrentEntries(EntryFilter{DaysBack: 3, DaysAhead: 7})
+	require.NoError(t, err)
+
+	summaries := make([]string, 0, len(entries))
+	for _, e := range entries {
+		summaries = append(summaries, e.Summary)
+	}
+
+	assert.Equal(t, []string{"Holiday", "Dentist"}, summaries, "Ongoing entries should be included, ended entries not")
+
+	entries, err = a.EntriesBetween(day(12), day(13))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "Holiday", entries[0].Summary)
+	assert.True(t, entries[0].AllDay)
+	assert.True(t, day(15).Equal(entries[0].End.Time), "The end should be stored")
+}
+
+func TestApp_Migrate_AllDay(t *testing.T) {
+	a := newTestApp(t)
+
+	src, err := a.FindSourceByName("manual")
+	require.NoError(t, err)
+
+	midnight := time.Date(2025, time.July, 1, 0, 0, 0, 0, data.LocalTimezone)
+
+	for _, e := range []data.Entry{
+		{Summary: "Birthday", Date: data.HumanTime{Time: midnight}},
+		{Summary: "Dentist", Date: data.HumanTime{Time: midnight.Add(10 * time.Hour)}},
+	} {
+		e.Source = src
+		require.NoError(t, a.CreateEntry(&e))
+	}
+
+	// Simulate a database from before the AllDay column
+	require.NoError(t, a.db.Migrator().DropColumn(&data.Entry{}, "AllDay"))
+	require.NoError(t, a.Migrate())
+
+	var entries data.Entries
+	require.NoError(t, a.db.Order("date").Find(&entries).Error)
+	require.Len(t, entries, 2)
+
+	assert.True(t, entries[0].AllDay, "Entries at midnight were shown as all day")
+	assert.False(t, entries[1].AllDay)
+}
+
+func TestApp_CurrentEntries_Recurring(t *testing.T) {
+	a := newTestApp(t)
+
+	a.clock = data.FixedClock(time.Date(2025, time.July, 10, 12, 0, 0, 0, data.LocalTimezone))
+
+	src, err := a.FindSourceByName("manual")
+	require.NoError(t, err)
+
+	e := data.Entry{
+		Source:  src,
+		Summary: "Swimming",
+		Date:    data.HumanTime{Time: time.Date(2025, time.January, 7, 18, 0, 0, 0, data.LocalTimezone)},
+	}
+	require.NoError(t, e.SetRepeat("FREQ=WEEKLY;BYDAY=TU"))
+	require.NoError(t, e.AddException("2025-07-15"))
+	require.NoError(t, a.CreateEntry(&e))
+
+	entries, err := a.CurrentEntries(EntryFilter{DaysBack: 3, DaysAhead: 14})
+	require.NoError(t, err)
+
+	days := make([]string, 0, len(entries))
+	for _, e := range entries {
+		days = append(days, e.Date.Format("2006-01-02"))
+	}
+
+	assert.Equal(t, []string{"2025-07-08", "2025-07-22"}, days, "Occurrences in the period should be expanded, except the skipped one")
+
+	series, err := a.RecurringEntries()
+	require.NoError(t, err)
+	require.Len(t, series, 1)
+
+	require.NoError(t, series[0].StopRepeat(time.Date(2025, time.July, 20, 0, 0, 0, 0, data.LocalTimezone)))
+	require.NoError(t, a.UpdateEntry(&series[0]))
+
+	entries, err = a.CurrentEntries(EntryFilter{DaysBack: 3, DaysAhead: 14})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "2025-07-08", entries[0].Date.Format("2006-01-02"))
+}
+
+func TestApp_UpdateEntry_KeptByImport(t *testing.T) {
+	a := newTestApp(t)
+
+	src := &data.Source{Name: "calendar"}
+	require.NoError(t, a.CreateSource(src))
+
+	day := time.Date(2025, time.July, 1, 0, 0, 0, 0, data.LocalTimezone)
+	imported := func() data.Entries {
+		return data.Entries{
+			{Summary: "Dentist", Date: data.HumanTime{Time: day}, Importance: data.MEDIUM},
+			{Summary: "Haircut", Date: data.HumanTime{Time: day}, Importance: data.MEDIUM},
+		}
+	}
+
+	entries := imported()
+	a.FetchExistingEntries(src.ID, entries)
+	require.NoError(t, a.ReplaceSourceEntries(src, entries))
+
+	e := &data.Entry{ID: entries[0].ID}
+	require.NoError(t, a.FindEntry(e))
+
+	remoteID := e.RemoteID
+	e.Summary = "Dentist, bring the papers"
+	e.SetMetadata("location", "Main street 1")
+	require.NoError(t, a.UpdateEntry(e))
+	require.NotNil(t, e.EditedAt)
+
+	entries = imported()
+	a.FetchExistingEntries(src.ID, entries)
+	require.NoError(t, a.ReplaceSourceEntries(src, entries))
+
+	stored := &data.Entry{ID: e.ID}
+	require.NoError(t, a.FindEntry(stored))
+	assert.Equal(t, "Dentist, bring the papers", stored.Summary, "The import should keep the edit")
+	assert.Equal(t, "Main street 1", stored.Metadata["location"])
+	assert.Equal(t, remoteID, stored.RemoteID, "The identity should be stable")
+
+	all, err := a.Entries()
+	require.NoError(t, err)
+	assert.Len(t, all, 2, "The import should not duplicate the edited entry")
+}
+
+func TestApp_UpdateEntry_RemovedByImport(t *testing.T) {
+	a := newTestApp(t)
+
+	src := &data.Source{Name: "calendar"}
+	require.NoError(t, a.CreateSource(src))
+
+	day := time.Date(2025, time.July, 1, 0, 0, 0, 0, data.LocalTimezone)
+
+	entries := data.Entries{{Summary: "Dentist", Date: data.HumanTime{Time: day}, Importance: data.MEDIUM}}
+	a.FetchExistingEntries(src.ID, entries)
+	require.NoError(t, a.ReplaceSourceEntries(src, entries))
+
+	e := &entries[0]
+	e.Summary = "Dentist, bring the papers"
+	require.NoError(t, a.UpdateEntry(e))
+	assert.False(t, e.Moved)
+
+	entries = data.Entries{{Summary: "Haircut", Date: data.HumanTime{Time: day}, Importance: data.MEDIUM}}
+	a.FetchExistingEntries(src.ID, entries)
+	require.NoError(t, a.ReplaceSourceEntries(src, entries))
+
+	all, err := a.Entries()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Haircut"}, summaries(all), "An edited entry that was removed from its source should be dropped")
+}
+
+func TestApp_UpdateEntry_MovedKeptByImport(t *testing.T) {
+	a := newTestApp(t)
+
+	calendar := &data.Source{Name: "calendar"}
+	require.NoError(t, a.CreateSource(calendar))
+
+	school := &data.Source{Name: "school"}
+	require.NoError(t, a.CreateSource(school))
+
+	day := time.Date(2025, time.July, 1, 0, 0, 0, 0, data.LocalTimezone)
+
+	entries := data.Entries{{Summary: "Sports day", Date: data.HumanTime{Time: day}, Importance: data.MEDIUM}}
+	a.FetchExistingEntries(calendar.ID, entries)
+	require.NoError(t, a.ReplaceSourceEntries(calendar, entries))
+
+	e := &data.Entry{ID: entries[0].ID}
+	require.NoError(t, a.FindEntry(e))
+
+	e.Source = school
+	e.SourceID = school.ID
+	e.AddTags("kids")
+	require.NoError(t, a.UpdateEntry(e))
+	assert.True(t, e.Moved)
+
+	entries = data.Entries{{Summary: "Parent evening", Date: data.HumanTime{Time: day}, Importance: data.MEDIUM}}
+	a.FetchExistingEntries(school.ID, entries)
+	require.NoError(t, a.ReplaceSourceEntries(school, entries))
+
+	require.NoError(t, a.ReplaceSourceEntries(calendar, data.Entries{}))
+
+	stored := &data.Entry{ID: e.ID}
+	require.NoError(t, a.FindEntry(stored), "The import of the new source should keep the moved entry")
+	assert.Equal(t, school.ID, stored.SourceID)
+	assert.Equal(t, []string{"kids"}, stored.TagNames(), "The tags of the moved entry should be kept")
+
+	all, err := a.Entries()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"Sports day", "Parent evening"}, summaries(all))
+}
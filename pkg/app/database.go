@@ -18,9 +18,47 @@ func (a *App) DB() *gorm.DB {
 }
 
 func (a *App) Migrate() error {
-	return a.db.AutoMigrate(
-		data.Source{}, data.Entry{},
-	)
+	m := a.db.Migrator()
+	addAllDay := m.HasTable(&data.Entry{}) && !m.HasColumn(&data.Entry{}, "AllDay")
+
+	if err := a.db.AutoMigrate(
+		data.Source{}, data.Entry{}, data.CachedResponse{}, data.UsageRecord{}, data.Embedding{}, data.Tag{},
+	); err != nil {
+		return err
+	}
+
+	if err := a.migrateSearch(); err != nil {
+		return err
+	}
+
+	if addAllDay {
+		return a.markAllDayEntries()
+	}
+
+	return nil
+}
+
+// markAllDayEntries marks existing entries at midnight as all day, since that
+// is how they were shown before the AllDay column existed
+func (a *App) markAllDayEntries() error {
+	var entries data.Entries
+
+	if err := a.db.Find(&entries).Error; err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		t := e.Date.In(data.LocalTimezone)
+		if t.Hour() != 0 || t.Minute() != 0 {
+			continue
+		}
+
+		if err := a.db.Model(&e).UpdateColumn("all_day", true).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 func (a *App) initializeDatabase() error {
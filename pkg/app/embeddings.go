@@ -0,0 +1,187 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/jovandeginste/spark-personal-assistant/pkg/ai"
+	"github.com/jovandeginste/spark-personal-assistant/pkg/data"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// embeddingBatchSize is the number of entries embedded per request
+	embeddingBatchSize = 64
+	defaultTopK        = 20
+)
+
+// RetrievalConfig configures the retrieval of relevant entries for questions
+type RetrievalConfig struct {
+	// TopK is the number of entries to retrieve
+	TopK int `mapstructure:"top_k"`
+}
+
+func (a *App) topK() int {
+	if a.Config.Retrieval.TopK > 0 {
+		return a.Config.Retrieval.TopK
+	}
+
+	return defaultTopK
+}
+
+// IndexEntries embeds new and changed entries with the embedding model of the
+// client, and deletes the embeddings of deleted entries. It returns the number
+// of entries that were embedded.
+func (a *App) IndexEntries(ctx context.Context, client ai.Client) (int, error) {
+	model := client.EmbeddingModel()
+	if model == "" {
+		return 0, ai.ErrNoEmbeddings
+	}
+
+	if err := a.db.
+		Where("entry_id NOT IN (?)", a.db.Model(&data.Entry{}).Select("id")).
+		Delete(&data.Embedding{}).Error; err != nil {
+		return 0, err
+	}
+
+	var entries data.Entries
+
+	if err := a.db.Preload("Tags").Find(&entries).Error; err != nil {
+		return 0, err
+	}
+
+	var existing []data.Embedding
+
+	if err := a.db.Select("entry_id", "hash").Where("model = ?", model).Find(&existing).Error; err != nil {
+		return 0, err
+	}
+
+	hashes := make(map[uint64]string, len(existing))
+	for _, e := range existing {
+		hashes[e.EntryID] = e.Hash
+	}
+
+	var changed data.Entries
+
+	for _, e := range entries {
+		if hashes[e.ID] != e.EmbeddingHash() {
+			changed = append(changed, e)
+		}
+	}
+
+	if len(changed) == 0 {
+		return 0, nil
+	}
+
+	a.Logger().Info("Embedding entries", "entries", len(changed), "model", model)
+
+	for batch := range slices.Chunk(changed, embeddingBatchSize) {
+		if err := a.embedEntries(ctx, client, model, batch); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(changed), nil
+}
+
+func (a *App) embedEntries(ctx context.Context, client ai.Client, model string, entries data.Entries) error {
+	texts := make([]string, 0, len(entries))
+	for _, e := range entries {
+		texts = append(texts, e.EmbeddingText())
+	}
+
+	vectors, err := client.Embed(ctx, texts)
+	if err != nil {
+		return err
+	}
+
+	if len(vectors) != len(entries) {
+		return fmt.Errorf("expected %d embeddings, got %d", len(entries), len(vectors))
+	}
+
+	embeddings := make([]data.Embedding, 0, len(entries))
+
+	for i, e := range entries {
+		embeddings = append(embeddings, data.Embedding{
+			EntryID: e.ID,
+			Model:   model,
+			Hash:    e.EmbeddingHash(),
+			Vector:  vectors[i],
+		})
+	}
+
+	return a.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&embeddings).Error
+}
+
+// RelevantEntries returns the entries of the source and tags of the filter
+// that are most similar to the query, sorted by date, regardless of the period
+// of the filter. Recurring entries are expanded in that period, or to their
+// next occurrence. New and changed entries are embedded first.
+func (a *App) RelevantEntries(ctx context.Context, client ai.Client, query string, ef EntryFilter) (data.Entries, error) {
+	if _, err := a.IndexEntries(ctx, client); err != nil {
+		return nil, err
+	}
+
+	vectors, err := client.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(vectors) != 1 {
+		return nil, errors.New("no embedding for the query")
+	}
+
+	var embeddings []data.Embedding
+
+	candidates := ef.Select(a.db.Model(&data.Entry{}).Select("id"))
+
+	if err := a.db.
+		Where("model = ?", client.EmbeddingModel()).
+		Where("entry_id IN (?)", candidates).
+		Find(&embeddings).Error; err != nil {
+		return nil, err
+	}
+
+	matches := data.TopMatches(vectors[0], embeddings, a.topK())
+
+	ids := make([]uint64, 0, len(matches))
+	for _, m := range matches {
+		ids = append(ids, m.EntryID)
+	}
+
+	var entries data.Entries
+
+	if err := a.DB().Where("id IN ?", ids).Order("date ASC").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	return a.expandRelevant(entries, ef), nil
+}
+
+// expandRelevant replaces the recurring entries by their occurrences in the
+// period of the filter, or by their next occurrence if there are none. Other
+// entries are kept, whatever their date.
+func (a *App) expandRelevant(entries data.Entries, ef EntryFilter) data.Entries {
+	now := a.Now()
+	result := make(data.Entries, 0, len(entries))
+
+	for _, e := range entries {
+		occurrences := data.Entries{e}.Expand(ef.From(now), ef.To(now))
+
+		if len(occurrences) == 0 {
+			if next, ok := e.NextOccurrence(now); ok {
+				occurrences = data.Entries{e}.Expand(next, next)
+			}
+		}
+
+		result = append(result, occurrences...)
+	}
+
+	slices.SortStableFunc(result, func(a, b data.Entry) int {
+		return a.Date.Compare(b.Date.Time)
+	})
+
+	return result
+}
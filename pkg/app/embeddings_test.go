@@ -0,0 +1,99 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jovandeginste/spark-personal-assistant/pkg/ai"
+	"github.com/jovandeginste/spark-personal-assistant/pkg/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApp_RelevantEntries(t *testing.T) {
+	a := newTestApp(t)
+	ctx := context.Background()
+
+	for _, e := range []struct{ date, title string }{
+		{"2024-09-12", "Plan the school trip to the zoo"},
+		{"2025-03-03", "Dentist Jane"},
+		{"2025-04-01", "Pay for the school trip"},
+	} {
+		_, err := findTool(t, a, "create_entry").Call(ctx, ai.ToolArgs{"title": e.title, "date": e.date})
+		require.NoError(t, err)
+	}
+
+	client, err := ai.NewClient(&ai.AIConfig{Type: "fake"}, ai.AssistantConfig{})
+	require.NoError(t, err)
+
+	n, err := a.IndexEntries(ctx, client)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	n, err = a.IndexEntries(ctx, client)
+	require.NoError(t, err)
+	assert.Zero(t, n, "Unchanged entries should not be embedded again")
+
+	a.Config.Retrieval.TopK = 2
+
+	entries, err := a.RelevantEntries(ctx, client, "What about the school trip?", EntryFilter{})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "Plan the school trip to the zoo", entries[0].Summary, "Entries should be sorted by date")
+	assert.Equal(t, "Pay for the school trip", entries[1].Summary)
+
+	e := entries[1]
+	e.Summary = "Pay for the school trip to the zoo"
+	require.NoError(t, a.DB().Save(&e).Error)
+
+	require.NoError(t, a.DeleteEntry(&entries[0]))
+
+	n, err = a.IndexEntries(ctx, client)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n, "Changed entries should be embedded again")
+
+	var count int64
+	require.NoError(t, a.db.Model(&data.Embedding{}).Count(&count).Error)
+	assert.Equal(t, int64(2), count, "Embeddings of deleted entries should be removed")
+
+	_, err = a.IndexEntries(ctx, noEmbeddings{client})
+	assert.ErrorIs(t, err, ai.ErrNoEmbeddings)
+}
+
+type noEmbeddings struct{ ai.Client }
+
+func (noEmbeddings) EmbeddingModel() string { return "" }
+
+func TestApp_RelevantEntries_Filter(t *testing.T) {
+	a := newTestApp(t)
+	a.clock = data.FixedClock(time.Date(2025, time.July, 10, 12, 0, 0, 0, data.LocalTimezone))
+	ctx := context.Background()
+
+	manual, err := a.FindSourceByName("manual")
+	require.NoError(t, err)
+
+	calendar := &data.Source{Name: "calendar"}
+	require.NoError(t, a.CreateSource(calendar))
+
+	lesson := data.Entry{Source: manual, Summary: "Swimming lesson", Date: data.HumanTime{Time: time.Date(2025, time.January, 6, 0, 0, 0, 0, data.LocalTimezone)}, AllDay: true}
+	require.NoError(t, lesson.SetRepeat("FREQ=WEEKLY"))
+	require.NoError(t, a.CreateEntry(&lesson))
+
+	gear := data.Entry{Source: calendar, Summary: "Buy swimming gear", Date: data.HumanTime{Time: time.Date(2025, time.March, 1, 0, 0, 0, 0, data.LocalTimezone)}, AllDay: true}
+	require.NoError(t, a.CreateEntry(&gear))
+
+	client, err := ai.NewClient(&ai.AIConfig{Type: "fake"}, ai.AssistantConfig{})
+	require.NoError(t, err)
+
+	entries, err := a.RelevantEntries(ctx, client, "swimming", EntryFilter{Source: manual, DaysAhead: 7})
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "Entries of other sources should not be retrieved")
+	assert.Equal(t, "Swimming lesson", entries[0].Summary)
+	assert.Equal(t, "2025-07-14", entries[0].FormattedDate(), "Series should be expanded in the period")
+
+	entries, err = a.RelevantEntries(ctx, client, "swimming", EntryFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2025-03-01", "2025-07-14"}, []string{entries[0].FormattedDate(), entries[1].FormattedDate()},
+		"Series without occurrences in the period should be expanded to the next one")
+}
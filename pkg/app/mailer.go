@@ -3,9 +3,14 @@ package app
 import (
 	"fmt"
 	"log/slog"
+	"slices"
+	"strings"
+	"text/template"
 	"time"
 	"unicode"
 
+	"github.com/jovandeginste/spark-personal-assistant/pkg/data"
+
 	"golang.org/x/text/runes"
 	"golang.org/x/text/transform"
 	"golang.org/x/text/unicode/norm"
@@ -43,16 +48,69 @@ func New(a *App) (*Mailer, error) {
 	return &m, nil
 }
 
-func (m *Mailer) Send(addresses []string, subject, md, html string) error {
-	for i := range addresses {
-		addresses[i] = normalizeString(addresses[i])
+// Subject is available in the subject template of a mail, formatted in the
+// locale of the recipients
+type Subject struct {
+	Date    string
+	Time    string
+	Weekday string
+	Week    int
+}
+
+// Mail is a mail to the recipients that share a locale, with the subject in
+// that locale
+type Mail struct {
+	To      []string
+	Subject string
+	Locale  data.Locale
+}
+
+// Mails groups the addresses by the locale of the recipients, and renders the
+// subject template in each locale
+func (m *Mailer) Mails(addresses []string, subject string, now time.Time) ([]Mail, error) {
+	tmpl, err := template.New("subject").Parse(subject)
+	if err != nil {
+		return nil, err
 	}
 
+	var result []Mail
+
+	for _, address := range addresses {
+		l := m.app.RecipientLocale(address)
+
+		i := slices.IndexFunc(result, func(ml Mail) bool { return ml.Locale == l })
+		if i >= 0 {
+			result[i].To = append(result[i].To, normalizeString(address))
+			continue
+		}
+
+		var b strings.Builder
+
+		if err := tmpl.Execute(&b, Subject{
+			Date:    now.Format("2006-01-02"),
+			Time:    now.Format(l.TimeLayout()),
+			Weekday: l.WeekdayName(now.Weekday()),
+			Week:    l.Week(now),
+		}); err != nil {
+			return nil, err
+		}
+
+		result = append(result, Mail{To: []string{normalizeString(address)}, Subject: b.String(), Locale: l})
+	}
+
+	return result, nil
+}
+
+// Send sends the mail, with the body as Markdown and as HTML. It keeps
+// retrying until the server accepts the mail.
+func (m *Mailer) Send(ml Mail, md, html string) {
+	addresses, subject := ml.To, ml.Subject
+
 	m.Logger().Info("Sending mail", "to", addresses, "subject", subject)
 
 	if m.Preview {
 		fmt.Println(md)
-		return nil
+		return
 	}
 
 	msg := gomail.NewMessage()
@@ -83,8 +141,6 @@ func (m *Mailer) Send(addresses []string, subject, md, html string) error {
 	}
 
 	m.Logger().Info("Mail sent", "to", addresses)
-
-	return nil
 }
 
 func normalizeString(s string) string {
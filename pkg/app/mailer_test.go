@@ -0,0 +1,71 @@
+package app
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jovandeginste/spark-personal-assistant/pkg/ai"
+	"github.com/jovandeginste/spark-personal-assistant/pkg/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMailer_mails(t *testing.T) {
+	a := &App{}
+	a.Config.Locale = data.Locale{Language: "Dutch"}
+	a.Config.Recipients = []Recipient{
+		{Address: "grandma@example.com", Locale: data.Locale{Language: "English", Clock: "12h", FirstWeekday: "sunday"}},
+	}
+	a.Config.Mailer.app = a
+
+	now := time.Date(2026, 1, 4, 15, 30, 0, 0, time.UTC)
+
+	mails, err := a.Config.Mailer.Mails(
+		[]string{"me@example.com", "Grandma@example.com", "partner@example.com"},
+		"Update for {{ .Weekday }} {{ .Date }} {{ .Time }}, week {{ .Week }}",
+		now,
+	)
+
+	require.NoError(t, err)
+	require.Len(t, mails, 2, "Each locale should get its own mail")
+
+	assert.Equal(t, []string{"me@example.com", "partner@example.com"}, mails[0].To)
+	assert.Equal(t, "Update for zondag 2026-01-04 15:30, week 1", mails[0].Subject, "The weekday should be in the language of the recipients")
+	assert.Equal(t, "Dutch", mails[0].Locale.Language)
+
+	assert.Equal(t, []string{"Grandma@example.com"}, mails[1].To)
+	assert.Equal(t, "Update for Sunday 2026-01-04 3:30 PM, week 2", mails[1].Subject)
+	assert.Equal(t, data.Locale{Language: "English", Units: "metric", Clock: "12h", FirstWeekday: "sunday"}, mails[1].Locale)
+
+	_, err = a.Config.Mailer.Mails([]string{"me@example.com"}, "{{ .Unknown }}", now)
+	assert.Error(t, err, "Unknown fields in the subject should return an error")
+}
+
+func TestApp_RecipientAssistant(t *testing.T) {
+	a := newTestApp(t)
+	a.Config.LLM = &ai.AIConfig{Type: "fake"}
+	a.Config.Assistant.Name = "Spark"
+	a.Config.Locale = data.Locale{Language: "Dutch"}
+	a.Config.Recipients = []Recipient{
+		{Address: "grandma@example.com", Locale: data.Locale{Language: "English", Units: "imperial"}},
+	}
+
+	p, err := a.Config.Formats.PromptFor("today")
+	require.NoError(t, err)
+
+	for address, expected := range map[string][]string{
+		"me@example.com":      {"Write your response in Dutch", "metric system"},
+		"grandma@example.com": {"Write your response in English", "imperial system"},
+	} {
+		aiClient, err := a.AIClientFor(a.RecipientAssistant(address))
+		require.NoError(t, err)
+
+		prompt, err := p(aiClient.Assistant(), nil)
+		require.NoError(t, err)
+
+		for _, s := range expected {
+			assert.Contains(t, strings.Join(prompt, "\n"), s, address)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package app
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/jovandeginste/spark-personal-assistant/pkg/ai"
+	"github.com/jovandeginste/spark-personal-assistant/pkg/data"
+)
+
+const (
+	defaultPersonasDir = "personas"
+	personaExtension   = ".md"
+)
+
+// Persona is a persona file in the personas directory
+type Persona struct {
+	ID   string // The file name without extension, to use with --persona
+	File string
+
+	ai.AssistantConfig
+}
+
+// isPersonaName tells whether --persona is the ID of a persona instead of the
+// path to a file
+func isPersonaName(s string) bool {
+	return !strings.ContainsRune(s, filepath.Separator) && filepath.Ext(s) == ""
+}
+
+// PersonasDir returns the directory with the personas, relative to the config
+// file
+func (a *App) PersonasDir() (string, error) {
+	dir := a.Config.PersonasDir
+	if dir == "" {
+		dir = defaultPersonasDir
+	}
+
+	return a.relativeToConfig(dir)
+}
+
+func (a *App) personaFile(id string) (string, error) {
+	dir, err := a.PersonasDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, id+personaExtension), nil
+}
+
+// PersonaIDs returns the IDs of all personas in the personas directory
+func (a *App) PersonaIDs() ([]string, error) {
+	dir, err := a.PersonasDir()
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	var ids []string
+
+	for _, f := range files {
+		if f.IsDir() || filepath.Ext(f.Name()) != personaExtension {
+			continue
+		}
+
+		ids = append(ids, strings.TrimSuffix(f.Name(), personaExtension))
+	}
+
+	slices.Sort(ids)
+
+	return ids, nil
+}
+
+// LoadPersona reads the persona with the ID from the personas directory
+func (a *App) LoadPersona(id string) (*Persona, error) {
+	file, err := a.personaFile(id)
+	if err != nil {
+		return nil, err
+	}
+
+	assistant, err := readPersona(file)
+	if err != nil {
+		return nil, err
+	}
+
+	assistant.Locale = a.Config.Locale.Merge(data.Locale{Language: assistant.Language}).WithDefaults()
+
+	return &Persona{ID: id, File: file, AssistantConfig: assistant}, nil
+}
+
+// ValidatePersona checks the persona, and whether its preferred provider is
+// configured
+func (a *App) ValidatePersona(p *Persona) error {
+	errs := []error{p.Validate()}
+
+	if p.Provider != "" {
+		configs := append([]*ai.AIConfig{a.Config.LLM}, a.Config.LLMFallbacks...)
+		configs = slices.DeleteFunc(configs, func(c *ai.AIConfig) bool { return c == nil })
+
+		if _, err := p.Configure(configs); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
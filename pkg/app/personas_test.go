@@ -0,0 +1,76 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jovandeginste/spark-personal-assistant/pkg/ai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writePersona(t *testing.T, dir, name, content string) {
+	t.Helper()
+
+	require.NoError(t, os.MkdirAll(dir, 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+}
+
+func TestApp_Personas(t *testing.T) {
+	dir := t.TempDir()
+	personas := filepath.Join(dir, "personas")
+
+	writePersona(t, personas, "uncle.md", "---\nname: Sjors\nlanguage: Dutch\ntemperature: 0.3\nmax_tokens: 800\nprovider: ollama\nmodel: mistral\ngreeting: with a wave\n---\nBe a friendly uncle.\n")
+	writePersona(t, personas, "lazy.md", "---\nname: Lazy\nprovider: openai\n---\nDo little.\n")
+	writePersona(t, personas, "notes.txt", "Not a persona")
+
+	app := &App{ConfigFile: filepath.Join(dir, "spark.yaml")}
+	app.Config.LLM = &ai.AIConfig{Type: "ollama"}
+
+	ids, err := app.PersonaIDs()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"lazy", "uncle"}, ids)
+
+	p, err := app.LoadPersona("uncle")
+	require.NoError(t, err)
+
+	temperature := 0.3
+	assert.Equal(t, filepath.Join(personas, "uncle.md"), p.File)
+	assert.Equal(t, "Sjors", p.Name)
+	assert.Equal(t, "Dutch", p.Language)
+	assert.Equal(t, "Dutch", p.Locale.Language, "The persona's language should be used in its prompt")
+	assert.Equal(t, &temperature, p.Temperature)
+	assert.Equal(t, 800, p.MaxTokens)
+	assert.Equal(t, "ollama", p.Provider)
+	assert.Equal(t, "mistral", p.Model)
+	assert.Equal(t, "with a wave", p.Greeting)
+	assert.Equal(t, "Be a friendly uncle.\n", p.Style)
+	require.NoError(t, app.ValidatePersona(p))
+
+	p, err = app.LoadPersona("lazy")
+	require.NoError(t, err)
+	assert.ErrorContains(t, app.ValidatePersona(p), "not configured: openai")
+
+	_, err = app.LoadPersona("missing")
+	assert.Error(t, err)
+
+	app.Config.PersonasDir = "elsewhere"
+
+	ids, err = app.PersonaIDs()
+	require.NoError(t, err)
+	assert.Empty(t, ids, "A missing directory has no personas")
+}
+
+func TestApp_setAssistantStylePath_PersonaName(t *testing.T) {
+	app := &App{ConfigFile: "/etc/spark/spark.yaml"}
+	app.Config.AssistantFileCLI = "chuck"
+
+	require.NoError(t, app.setAssistantStylePath())
+	assert.Equal(t, "/etc/spark/personas/chuck.md", app.Config.AssistantFile)
+
+	app.Config.AssistantFileCLI = "my/chuck.md"
+
+	require.NoError(t, app.setAssistantStylePath())
+	assert.Equal(t, "/etc/spark/my/chuck.md", app.Config.AssistantFile, "Paths should still be relative to the config file")
+}
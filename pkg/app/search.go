@@ -0,0 +1,160 @@
+package app
+
+import (
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/jovandeginste/spark-personal-assistant/pkg/data"
+)
+
+// searchTriggers keep the full-text index in sync with the entries table on
+// every insert, update and delete
+var searchTriggers = []string{
+	`CREATE TRIGGER IF NOT EXISTS entries_fts_insert AFTER INSERT ON entries BEGIN
+		INSERT INTO entries_fts(rowid, summary, metadata) VALUES (new.id, new.summary, ` + searchTextOf("new") + `);
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS entries_fts_update AFTER UPDATE ON entries BEGIN
+		DELETE FROM entries_fts WHERE rowid = old.id;
+		INSERT INTO entries_fts(rowid, summary, metadata) VALUES (new.id, new.summary, ` + searchTextOf("new") + `);
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS entries_fts_delete AFTER DELETE ON entries BEGIN
+		DELETE FROM entries_fts WHERE rowid = old.id;
+	END`,
+}
+
+// searchTextOf is the SQL for the text in the metadata of the entry in table:
+// all string values, also nested ones like attendees
+func searchTextOf(table string) string {
+	return "(SELECT group_concat(value, ' ') FROM json_tree(" + table + ".metadata) WHERE type = 'text')"
+}
+
+// SearchFilter narrows a full-text search; zero values do not filter
+type SearchFilter struct {
+	From   time.Time
+	To     time.Time
+	Source *data.Source
+	Limit  int
+}
+
+// migrateSearch creates the full-text index of the entries, and fills it if
+// it is new
+func (a *App) migrateSearch() error {
+	isNew := !a.db.Migrator().HasTable("entries_fts")
+
+	if err := a.db.Exec(
+		"CREATE VIRTUAL TABLE IF NOT EXISTS entries_fts USING fts5(summary, metadata, tokenize = 'unicode61 remove_diacritics 2')",
+	).Error; err != nil {
+		return err
+	}
+
+	for _, t := range searchTriggers {
+		if err := a.db.Exec(t).Error; err != nil {
+			return err
+		}
+	}
+
+	if !isNew {
+		return nil
+	}
+
+	return a.db.Exec(
+		"INSERT INTO entries_fts(rowid, summary, metadata) SELECT id, summary, " + searchTextOf("entries") + " FROM entries",
+	).Error
+}
+
+// ftsQuery turns the words of a query into an FTS5 query that matches the
+// entries with all words, or words that start with them
+func ftsQuery(query string) string {
+	words := strings.FieldsFunc(query, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	terms := make([]string, 0, len(words))
+	for _, w := range words {
+		terms = append(terms, `"`+w+`"*`)
+	}
+
+	return strings.Join(terms, " ")
+}
+
+// FullTextSearch returns the entries that match the words of the query in
+// their summary or metadata, best matches first. Recurring entries are
+// replaced by their occurrences in the period of the filter, like in
+// EntriesBetween, if it has one.
+func (a *App) FullTextSearch(query string, sf SearchFilter) (data.Entries, error) {
+	match := ftsQuery(query)
+	if match == "" {
+		return data.Entries{}, nil
+	}
+
+	q := a.DB().
+		Joins("JOIN entries_fts ON entries_fts.rowid = entries.id").
+		Where("entries_fts MATCH ?", match)
+
+	if !sf.From.IsZero() {
+		q = q.Where("entries.date >= ? OR entries.ends_at > ? OR entries.repeat <> ''", sf.From, sf.From)
+	}
+
+	if !sf.To.IsZero() {
+		q = q.Where("entries.date <= ?", sf.To)
+	}
+
+	if sf.Source != nil {
+		q = q.Where("entries.source_id = ?", sf.Source.ID)
+	}
+
+	// Recurring entries may have no occurrences in the period, so the limit
+	// is applied after expanding them
+	windowed := !sf.From.IsZero() || !sf.To.IsZero()
+
+	if sf.Limit > 0 && !windowed {
+		q = q.Limit(sf.Limit)
+	}
+
+	var entries data.Entries
+
+	// Matches in the summary weigh more than in the metadata
+	if err := q.Order("bm25(entries_fts, 10.0, 1.0)").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	if windowed {
+		entries = sf.expand(entries)
+	}
+
+	if sf.Limit > 0 && len(entries) > sf.Limit {
+		entries = entries[:sf.Limit]
+	}
+
+	return entries, nil
+}
+
+// expand replaces the recurring entries by their occurrences in the period of
+// the filter, keeping the order of the matches. Without an end, only the next
+// occurrence is used.
+func (sf SearchFilter) expand(entries data.Entries) data.Entries {
+	result := make(data.Entries, 0, len(entries))
+
+	for _, e := range entries {
+		if e.Repeat == "" {
+			result = append(result, e)
+			continue
+		}
+
+		from, to := sf.From, sf.To
+
+		if to.IsZero() {
+			next, ok := e.NextOccurrence(from)
+			if !ok {
+				continue
+			}
+
+			from, to = next, next
+		}
+
+		result = append(result, data.Entries{e}.Expand(from, to)...)
+	}
+
+	return result
+}
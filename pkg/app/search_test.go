@@ -0,0 +1,122 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jovandeginste/spark-personal-assistant/pkg/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ftsQuery(t *testing.T) {
+	assert.Equal(t, `"plumber"* "appointment"*`, ftsQuery("plumber appointment"))
+	assert.Equal(t, `"O"* "Reilly"* "café"*`, ftsQuery(`O'Reilly "café" -`))
+	assert.Empty(t, ftsQuery(" - "))
+}
+
+func TestApp_FullTextSearch(t *testing.T) {
+	a := newTestApp(t)
+
+	manual, err := a.FindSourceByName("manual")
+	require.NoError(t, err)
+
+	calendar := &data.Source{Name: "calendar"}
+	require.NoError(t, a.CreateSource(calendar))
+
+	day := func(m time.Month, d int) time.Time { return time.Date(2025, m, d, 0, 0, 0, 0, data.LocalTimezone) }
+
+	plumber := data.Entry{Source: manual, Summary: "Plumber appointment", Date: data.HumanTime{Time: day(time.February, 3)}}
+	require.NoError(t, a.CreateEntry(&plumber))
+
+	dentist := data.Entry{Source: manual, Summary: "Dentist", Date: data.HumanTime{Time: day(time.March, 3)}}
+	dentist.SetMetadata("description", "Ask about the plumbing of the office")
+	require.NoError(t, a.CreateEntry(&dentist))
+
+	entries := data.Entries{{
+		Summary:  "Team meeting",
+		Date:     data.HumanTime{Time: day(time.April, 1)},
+		Metadata: map[string]any{"attendees": []any{"Mario the plumber", "Luigi"}},
+	}}
+	a.FetchExistingEntries(calendar.ID, entries)
+	require.NoError(t, a.ReplaceSourceEntries(calendar, entries))
+
+	got, err := a.FullTextSearch("plumb", SearchFilter{})
+	require.NoError(t, err)
+	require.Len(t, got, 3)
+	assert.Equal(t, "Plumber appointment", got[0].Summary, "Matches in the summary should rank first")
+	assert.ElementsMatch(t, []string{"Dentist", "Team meeting"}, summaries(got[1:]))
+
+	got, err = a.FullTextSearch("plumb", SearchFilter{From: day(time.March, 1), To: day(time.March, 31)})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Dentist"}, summaries(got))
+
+	got, err = a.FullTextSearch("plumb", SearchFilter{Source: calendar})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Team meeting"}, summaries(got))
+
+	got, err = a.FullTextSearch("plumb", SearchFilter{Limit: 1})
+	require.NoError(t, err)
+	assert.Len(t, got, 1)
+
+	// Updates and deletes are indexed too
+	plumber.Summary = "Electrician appointment"
+	require.NoError(t, a.UpdateEntry(&plumber))
+	require.NoError(t, a.DeleteEntry(&dentist))
+	require.NoError(t, a.ReplaceSourceEntries(calendar, data.Entries{}))
+
+	got, err = a.FullTextSearch("plumb", SearchFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, got)
+
+	got, err = a.FullTextSearch("electrician", SearchFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Electrician appointment"}, summaries(got))
+}
+
+func TestApp_FullTextSearch_Recurring(t *testing.T) {
+	a := newTestApp(t)
+
+	manual, err := a.FindSourceByName("manual")
+	require.NoError(t, err)
+
+	day := func(m time.Month, d int) time.Time { return time.Date(2025, m, d, 0, 0, 0, 0, data.LocalTimezone) }
+
+	class := data.Entry{Source: manual, Summary: "Plumbing class", Date: data.HumanTime{Time: day(time.January, 6)}, AllDay: true}
+	require.NoError(t, class.SetRepeat("FREQ=WEEKLY;UNTIL=20250310"))
+	require.NoError(t, a.CreateEntry(&class))
+
+	got, err := a.FullTextSearch("plumb", SearchFilter{From: day(time.March, 1), To: day(time.March, 31)})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2025-03-03", "2025-03-10"}, dates(got), "Series should be expanded in the period")
+
+	got, err = a.FullTextSearch("plumb", SearchFilter{From: day(time.April, 1)})
+	require.NoError(t, err)
+	assert.Empty(t, got, "Series that ended should not match")
+
+	got, err = a.FullTextSearch("plumb", SearchFilter{From: day(time.February, 1), Limit: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2025-02-03"}, dates(got), "Without an end, the next occurrence should be used")
+
+	got, err = a.FullTextSearch("plumb", SearchFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2025-01-06"}, dates(got), "Without a period, the series should be returned")
+}
+
+func TestApp_migrateSearch_Backfill(t *testing.T) {
+	a := newTestApp(t)
+
+	manual, err := a.FindSourceByName("manual")
+	require.NoError(t, err)
+
+	e := data.Entry{Source: manual, Summary: "Dentist", Date: data.HumanTime{Time: time.Now()}}
+	e.SetMetadata("location", "Main street")
+	require.NoError(t, a.CreateEntry(&e))
+
+	require.NoError(t, a.db.Exec("DROP TABLE entries_fts").Error)
+	require.NoError(t, a.Migrate())
+
+	got, err := a.FullTextSearch("main street", SearchFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Dentist"}, summaries(got), "Existing entries should be indexed")
+}
@@ -0,0 +1,67 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+
+	"github.com/jovandeginste/spark-personal-assistant/pkg/data"
+)
+
+// TagRule tags imported entries: the entries of the sources, or of all
+// sources if there are none, whose title matches the regular expression, or
+// all their entries if there is none
+type TagRule struct {
+	Tag     string   `mapstructure:"tag"`
+	Sources []string `mapstructure:"sources"`
+	Match   string   `mapstructure:"match"`
+
+	match *regexp.Regexp
+}
+
+func (r *TagRule) applies(src *data.Source, e *data.Entry) bool {
+	if len(r.Sources) > 0 && !slices.Contains(r.Sources, src.Name) {
+		return false
+	}
+
+	return r.match == nil || r.match.MatchString(e.Summary)
+}
+
+func (a *App) loadTagRules() error {
+	for i := range a.Config.TagRules {
+		r := &a.Config.TagRules[i]
+
+		if len(data.NormalizeTags([]string{r.Tag})) == 0 {
+			return fmt.Errorf("tag rule %d has no tag", i+1)
+		}
+
+		if r.Match == "" {
+			continue
+		}
+
+		re, err := regexp.Compile(r.Match)
+		if err != nil {
+			return fmt.Errorf("tag rule %d: %w", i+1, err)
+		}
+
+		r.match = re
+	}
+
+	return nil
+}
+
+// tagEntries applies the tag rules to the imported entries of the source
+func (a *App) tagEntries(src *data.Source, entries data.Entries) {
+	for i := range entries {
+		for _, r := range a.Config.TagRules {
+			if r.applies(src, &entries[i]) {
+				entries[i].AddTags(r.Tag)
+			}
+		}
+	}
+}
+
+// deleteOrphanTags removes the tags of entries that no longer exist
+func (a *App) deleteOrphanTags() error {
+	return a.db.Exec("DELETE FROM entry_tags WHERE entry_id NOT IN (SELECT id FROM entries)").Error
+}
@@ -0,0 +1,122 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jovandeginste/spark-personal-assistant/pkg/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func summaries(entries data.Entries) []string {
+	result := make([]string, 0, len(entries))
+	for _, e := range entries {
+		result = append(result, e.Summary)
+	}
+
+	return result
+}
+
+func dates(entries data.Entries) []string {
+	result := make([]string, 0, len(entries))
+	for _, e := range entries {
+		result = append(result, e.FormattedDate())
+	}
+
+	return result
+}
+
+func TestApp_loadTagRules(t *testing.T) {
+	a := newTestApp(t)
+
+	a.Config.TagRules = []TagRule{{Tag: "kids", Match: "(?i)school"}}
+	require.NoError(t, a.loadTagRules())
+
+	a.Config.TagRules = []TagRule{{Tag: "kids", Match: "(school"}}
+	require.Error(t, a.loadTagRules())
+
+	a.Config.TagRules = []TagRule{{Tag: " ", Match: "school"}}
+	require.Error(t, a.loadTagRules())
+}
+
+func TestApp_ReplaceSourceEntries_TagRules(t *testing.T) {
+	a := newTestApp(t)
+
+	a.clock = data.FixedClock(time.Date(2025, time.July, 10, 12, 0, 0, 0, data.LocalTimezone))
+
+	school := &data.Source{Name: "school"}
+	require.NoError(t, a.CreateSource(school))
+
+	a.Config.TagRules = []TagRule{
+		{Tag: "kids", Sources: []string{"school"}},
+		{Tag: "outdoor", Match: "(?i)trip|swimming"},
+	}
+	require.NoError(t, a.loadTagRules())
+
+	day := time.Date(2025, time.July, 11, 0, 0, 0, 0, data.LocalTimezone)
+	entries := data.Entries{
+		{Summary: "Parent evening", Date: data.HumanTime{Time: day}},
+		{Summary: "School trip", Date: data.HumanTime{Time: day}},
+	}
+
+	a.FetchExistingEntries(school.ID, entries)
+	require.NoError(t, a.ReplaceSourceEntries(school, entries))
+
+	manual, err := a.FindSourceByName("manual")
+	require.NoError(t, err)
+
+	work := data.Entry{Source: manual, Summary: "Board meeting", Date: data.HumanTime{Time: day}}
+	work.AddTags("work")
+	require.NoError(t, a.CreateEntry(&work))
+
+	ef := EntryFilter{DaysAhead: 7, Tags: []string{"kids"}}
+	got, err := a.CurrentEntries(ef)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Parent evening", "School trip"}, summaries(got))
+	assert.Equal(t, []string{"kids", "outdoor"}, got[1].TagNames())
+
+	ef = EntryFilter{DaysAhead: 7, ExcludeTags: []string{"Outdoor"}}
+	got, err = a.CurrentEntries(ef)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Parent evening", "Board meeting"}, summaries(got))
+
+	// Changed rules apply to the next import
+	a.Config.TagRules = a.Config.TagRules[1:]
+
+	entries = data.Entries{{Summary: "School trip", Date: data.HumanTime{Time: day}}}
+	a.FetchExistingEntries(school.ID, entries)
+	require.NoError(t, a.ReplaceSourceEntries(school, entries))
+
+	got, err = a.CurrentEntries(EntryFilter{DaysAhead: 7, Tags: []string{"kids", "outdoor"}})
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, []string{"outdoor"}, got[0].TagNames())
+
+	var orphans int64
+	require.NoError(t, a.db.Table("entry_tags").Where("entry_id NOT IN (SELECT id FROM entries)").Count(&orphans).Error)
+	assert.Zero(t, orphans, "The tags of removed entries should be removed")
+}
+
+func TestApp_UpdateEntry_Tags(t *testing.T) {
+	a := newTestApp(t)
+
+	src, err := a.FindSourceByName("manual")
+	require.NoError(t, err)
+
+	e := data.Entry{Source: src, Summary: "Swimming", Date: data.HumanTime{Time: time.Date(2025, time.July, 11, 0, 0, 0, 0, data.LocalTimezone)}}
+	e.AddTags("kids", "sports")
+	require.NoError(t, a.CreateEntry(&e))
+
+	stored := &data.Entry{ID: e.ID}
+	require.NoError(t, a.FindEntry(stored))
+	assert.Equal(t, []string{"kids", "sports"}, stored.TagNames())
+
+	stored.RemoveTags("sports")
+	stored.AddTags("outdoor")
+	require.NoError(t, a.UpdateEntry(stored))
+
+	stored = &data.Entry{ID: e.ID}
+	require.NoError(t, a.FindEntry(stored))
+	assert.Equal(t, []string{"kids", "outdoor"}, stored.TagNames())
+}
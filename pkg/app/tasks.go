@@ -0,0 +1,38 @@
+package app
+
+import (
+	"github.com/jovandeginste/spark-personal-assistant/pkg/data"
+)
+
+// Tasks returns the open tasks, or all tasks, the first due first
+func (a *App) Tasks(all bool) (data.Entries, error) {
+	q := a.DB().Where("status <> ''")
+
+	if !all {
+		q = q.Where("status = ?", data.OPEN)
+	}
+
+	var tasks data.Entries
+
+	if err := q.Order("due_at IS NULL, due_at ASC, date ASC").Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+// OverdueTasks returns the open tasks of the source and tags of the filter
+// that were due before today, however long ago
+func (a *App) OverdueTasks(ef EntryFilter) (data.Entries, error) {
+	var tasks data.Entries
+
+	if err := ef.Select(a.DB()).
+		Where("status = ?", data.OPEN).
+		Where("due_at < ?", a.Today()).
+		Order("due_at ASC").
+		Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
+}
@@ -0,0 +1,78 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jovandeginste/spark-personal-assistant/pkg/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApp_Tasks(t *testing.T) {
+	a := newTestApp(t)
+
+	a.clock = data.FixedClock(time.Date(2025, time.July, 10, 12, 0, 0, 0, data.LocalTimezone))
+
+	src, err := a.FindSourceByName("manual")
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		title, date, due string
+		status           data.TaskStatus
+	}{
+		{"Renew passport", "2025-03-01", "2025-04-01", data.OPEN},
+		{"Call plumber", "2025-07-01", "2025-07-20", data.OPEN},
+		{"Read book", "2025-07-01", "", data.OPEN},
+		{"Water plants", "2025-03-01", "2025-03-02", data.DONE},
+		{"Paint fence", "2025-07-09", "2025-07-09", data.CANCELLED},
+	} {
+		e := data.Entry{Source: src, Summary: tt.title, Status: tt.status}
+		require.NoError(t, e.SetDate(tt.date, a.Now()))
+		require.NoError(t, e.SetDue(tt.due, a.Now()))
+		require.NoError(t, a.CreateEntry(&e))
+	}
+
+	event := data.Entry{Source: src, Summary: "Dentist"}
+	require.NoError(t, event.SetDate("2025-07-11", a.Now()))
+	require.NoError(t, a.CreateEntry(&event))
+
+	tasks, err := a.Tasks(false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Renew passport", "Call plumber", "Read book"}, summaries(tasks), "Open tasks should be sorted by due date")
+
+	tasks, err = a.Tasks(true)
+	require.NoError(t, err)
+	assert.Len(t, tasks, 5)
+
+	overdue, err := a.OverdueTasks(EntryFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Renew passport"}, summaries(overdue))
+
+	other := &data.Source{Name: "school"}
+	require.NoError(t, a.CreateSource(other))
+
+	overdue[0].AddTags("admin")
+	require.NoError(t, a.UpdateEntry(&overdue[0]))
+
+	for _, ef := range []EntryFilter{
+		{Source: other},
+		{Tags: []string{"kids"}},
+		{ExcludeTags: []string{"admin"}},
+	} {
+		filtered, err := a.OverdueTasks(ef)
+		require.NoError(t, err)
+		assert.Empty(t, filtered, "The filter should apply to overdue tasks: %+v", ef)
+	}
+
+	filtered, err := a.OverdueTasks(EntryFilter{Source: src, Tags: []string{"admin"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Renew passport"}, summaries(filtered))
+
+	overdue[0].Complete(a.Now())
+	require.NoError(t, a.UpdateEntry(&overdue[0]))
+
+	overdue, err = a.OverdueTasks(EntryFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, overdue)
+}
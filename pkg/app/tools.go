@@ -0,0 +1,136 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/jovandeginste/spark-personal-assistant/pkg/ai"
+	"github.com/jovandeginste/spark-personal-assistant/pkg/data"
+)
+
+// toolSearchLimit is the maximum number of entries search_entries returns
+const toolSearchLimit = 20
+
+// ChatTools returns the tools the model may use during a chat to query and
+// update the entry database
+func (a *App) ChatTools() []ai.Tool {
+	return []ai.Tool{
+		{
+			Name:        "search_entries",
+			Description: "Search all entries for words in their title or details, regardless of their date. The best matches come first.",
+			Parameters: []ai.ToolParameter{
+				{Name: "query", Type: "string", Description: "The text to search for", Required: true},
+			},
+			Call: a.toolSearchEntries,
+		},
+		{
+			Name:        "list_entries",
+			Description: "List all entries between two dates, inclusive.",
+			Parameters: []ai.ToolParameter{
+				{Name: "from", Type: "string", Description: "The first date, formatted as YYYY-MM-DD", Required: true},
+				{Name: "to", Type: "string", Description: "The last date, formatted as YYYY-MM-DD", Required: true},
+			},
+			Call: a.toolListEntries,
+		},
+		{
+			Name:        "get_source",
+			Description: "Get the description of a source of entries, or list all sources if no name is given.",
+			Parameters: []ai.ToolParameter{
+				{Name: "name", Type: "string", Description: "The name of the source"},
+			},
+			Call: a.toolGetSource,
+		},
+		{
+			Name:        "get_weather",
+			Description: "Get the weather forecast for a day.",
+			Parameters: []ai.ToolParameter{
+				{Name: "date", Type: "string", Description: "The date, formatted as YYYY-MM-DD", Required: true},
+			},
+			Call: a.toolGetWeather,
+		},
+		{
+			Name:        "create_entry",
+			Description: "Create a new entry. Only use this when your employer explicitly asks to add or remember something.",
+			Parameters: []ai.ToolParameter{
+				{Name: "title", Type: "string", Description: "The title of the entry", Required: true},
+				{Name: "date", Type: "string", Description: "The date, formatted as YYYY-MM-DD", Required: true},
+				{Name: "importance", Type: "string", Description: "The importance: low, medium or high"},
+				{Name: "source", Type: "string", Description: "The name of the source; defaults to manual"},
+			},
+			Call: a.toolCreateEntry,
+		},
+	}
+}
+
+func parseToolDate(d string) (time.Time, error) {
+	return time.ParseInLocation("2006-01-02", d, data.LocalTimezone)
+}
+
+func (a *App) toolSearchEntries(_ context.Context, args ai.ToolArgs) (any, error) {
+	return a.FullTextSearch(args.String("query"), SearchFilter{Limit: toolSearchLimit})
+}
+
+func (a *App) toolListEntries(_ context.Context, args ai.ToolArgs) (any, error) {
+	from, err := parseToolDate(args.String("from"))
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := parseToolDate(args.String("to"))
+	if err != nil {
+		return nil, err
+	}
+
+	return a.EntriesBetween(from, to.AddDate(0, 0, 1).Add(-time.Second))
+}
+
+func (a *App) toolGetSource(_ context.Context, args ai.ToolArgs) (any, error) {
+	name := args.String("name")
+	if name == "" {
+		return a.Sources()
+	}
+
+	return a.FindSourceByName(name)
+}
+
+func (a *App) toolGetWeather(_ context.Context, args ai.ToolArgs) (any, error) {
+	day, err := parseToolDate(args.String("date"))
+	if err != nil {
+		return nil, err
+	}
+
+	return a.WeatherForDay(day)
+}
+
+func (a *App) toolCreateEntry(_ context.Context, args ai.ToolArgs) (any, error) {
+	name := args.String("source")
+	if name == "" {
+		name = "manual"
+	}
+
+	src, err := a.FindSourceByName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	importance := args.String("importance")
+	if importance == "" {
+		importance = string(data.MEDIUM)
+	}
+
+	e := data.Entry{Summary: args.String("title"), Source: src}
+
+	if err := e.SetDate(args.String("date"), a.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := e.SetImportance(importance); err != nil {
+		return nil, err
+	}
+
+	if err := a.CreateEntry(&e); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
@@ -0,0 +1,113 @@
+package app
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jovandeginste/spark-personal-assistant/pkg/ai"
+	"github.com/jovandeginste/spark-personal-assistant/pkg/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestApp returns an app with an empty database and a "manual" source
+func newTestApp(t *testing.T) *App {
+	t.Helper()
+
+	a := NewApp()
+	a.Config.Database.File = filepath.Join(t.TempDir(), "spark.db")
+	a.initializeLogger()
+
+	require.NoError(t, a.initializeDatabase())
+	require.NoError(t, a.CreateSource(&data.Source{Name: "manual"}))
+
+	return a
+}
+
+func findTool(t *testing.T, a *App, name string) ai.Tool {
+	t.Helper()
+
+	for _, tool := range a.ChatTools() {
+		if tool.Name == name {
+			return tool
+		}
+	}
+
+	t.Fatalf("tool %s not found", name)
+
+	return ai.Tool{}
+}
+
+func TestApp_ChatTools(t *testing.T) {
+	a := newTestApp(t)
+	ctx := context.Background()
+
+	for _, e := range []struct{ date, title string }{
+		{"2025-03-03", "Dentist Jane"},
+		{"2025-03-10", "Weather for Monday in Brussels"},
+		{"2025-04-01", "Dentist John"},
+	} {
+		_, err := findTool(t, a, "create_entry").Call(ctx, ai.ToolArgs{"title": e.title, "date": e.date})
+		require.NoError(t, err)
+	}
+
+	t.Run("search_entries", func(t *testing.T) {
+		result, err := findTool(t, a, "search_entries").Call(ctx, ai.ToolArgs{"query": "Dentist"})
+		require.NoError(t, err)
+
+		assert.ElementsMatch(t, []string{"Dentist Jane", "Dentist John"}, summaries(result.(data.Entries)))
+
+		result, err = findTool(t, a, "search_entries").Call(ctx, ai.ToolArgs{"query": "jane dent"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Dentist Jane"}, summaries(result.(data.Entries)), "All words should match, as prefixes")
+	})
+
+	t.Run("list_entries", func(t *testing.T) {
+		result, err := findTool(t, a, "list_entries").Call(ctx, ai.ToolArgs{"from": "2025-03-01", "to": "2025-03-31"})
+		require.NoError(t, err)
+		assert.Len(t, result.(data.Entries), 2, "Only entries in March should be listed")
+	})
+
+	t.Run("list_entries with invalid date", func(t *testing.T) {
+		_, err := findTool(t, a, "list_entries").Call(ctx, ai.ToolArgs{"from": "March", "to": "2025-03-31"})
+		assert.Error(t, err)
+	})
+
+	t.Run("get_weather", func(t *testing.T) {
+		result, err := findTool(t, a, "get_weather").Call(ctx, ai.ToolArgs{"date": "2025-03-10"})
+		require.NoError(t, err)
+
+		entries := result.(data.Entries)
+		require.Len(t, entries, 1)
+		assert.Equal(t, "Weather for Monday in Brussels", entries[0].Summary)
+	})
+
+	t.Run("get_source", func(t *testing.T) {
+		result, err := findTool(t, a, "get_source").Call(ctx, ai.ToolArgs{"name": "manual"})
+		require.NoError(t, err)
+		assert.Equal(t, "manual", result.(*data.Source).Name)
+	})
+
+	t.Run("create_entry", func(t *testing.T) {
+		result, err := findTool(t, a, "create_entry").Call(ctx, ai.ToolArgs{"title": "Call plumber", "date": "2025-03-05", "importance": "high"})
+		require.NoError(t, err)
+
+		e := result.(data.Entry)
+		assert.NotZero(t, e.ID)
+		assert.Equal(t, data.HIGH, e.Importance)
+
+		entries, err := a.EntriesBetween(
+			time.Date(2025, 3, 5, 0, 0, 0, 0, data.LocalTimezone),
+			time.Date(2025, 3, 5, 23, 59, 59, 0, data.LocalTimezone),
+		)
+		require.NoError(t, err)
+		assert.Len(t, entries, 1, "Entry should be stored")
+	})
+
+	t.Run("create_entry with unknown source", func(t *testing.T) {
+		_, err := findTool(t, a, "create_entry").Call(ctx, ai.ToolArgs{"title": "x", "date": "2025-03-05", "source": "nope"})
+		assert.Error(t, err)
+	})
+}
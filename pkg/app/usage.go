@@ -0,0 +1,108 @@
+package app
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/jovandeginste/spark-personal-assistant/pkg/ai"
+	"github.com/jovandeginste/spark-personal-assistant/pkg/data"
+)
+
+// Price is the price of a model in the currency of your choice per million
+// tokens; an empty provider matches all providers
+type Price struct {
+	Provider   string  `mapstructure:"provider"`
+	Model      string  `mapstructure:"model"`
+	Prompt     float64 `mapstructure:"prompt"`
+	Completion float64 `mapstructure:"completion"`
+}
+
+// TrackUsage returns a context that stores the usage of every call to the LLM
+// made with it
+func (a *App) TrackUsage(ctx context.Context, command, format string) context.Context {
+	return ai.WithUsageFunc(ctx, func(u ai.Usage) {
+		r := &data.UsageRecord{
+			CreatedAt:        a.Now(),
+			Command:          command,
+			Format:           format,
+			Persona:          a.Config.Assistant.Name,
+			Provider:         u.Provider,
+			Model:            u.Model,
+			PromptTokens:     u.PromptTokens,
+			CompletionTokens: u.CompletionTokens,
+		}
+
+		if err := a.db.Create(r).Error; err != nil {
+			a.Logger().Warn("Could not store LLM usage", "error", err)
+		}
+	})
+}
+
+// UsageReport returns the usage since the given time per day, or per month,
+// with the cost estimated from the price table
+func (a *App) UsageReport(since time.Time, monthly bool) (data.UsageReport, error) {
+	var records []data.UsageRecord
+
+	if err := a.db.Where("created_at >= ?", since).Order("created_at ASC").Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	layout := "2006-01-02"
+	if monthly {
+		layout = "2006-01"
+	}
+
+	var report data.UsageReport
+
+	for _, r := range records {
+		u := data.UsageTotal{
+			Period:   r.CreatedAt.In(data.LocalTimezone).Format(layout),
+			Command:  r.Command,
+			Format:   r.Format,
+			Provider: r.Provider,
+			Model:    r.Model,
+		}
+
+		i := slices.IndexFunc(report, func(t data.UsageTotal) bool {
+			return t.Period == u.Period && t.Command == u.Command && t.Format == u.Format &&
+				t.Provider == u.Provider && t.Model == u.Model
+		})
+		if i < 0 {
+			report = append(report, u)
+			i = len(report) - 1
+		}
+
+		report[i].Calls++
+		report[i].PromptTokens += r.PromptTokens
+		report[i].CompletionTokens += r.CompletionTokens
+	}
+
+	for i, u := range report {
+		p, ok := a.price(u.Provider, u.Model)
+		if !ok {
+			continue
+		}
+
+		report[i].Priced = true
+		report[i].Cost = (float64(u.PromptTokens)*p.Prompt + float64(u.CompletionTokens)*p.Completion) / 1_000_000
+	}
+
+	// Most expensive first within each period
+	slices.SortStableFunc(report, func(x, y data.UsageTotal) int {
+		return cmp.Or(cmp.Compare(x.Period, y.Period), cmp.Compare(y.Cost, x.Cost))
+	})
+
+	return report, nil
+}
+
+func (a *App) price(provider, model string) (Price, bool) {
+	for _, p := range a.Config.Prices {
+		if p.Model == model && (p.Provider == "" || p.Provider == provider) {
+			return p, true
+		}
+	}
+
+	return Price{}, false
+}
@@ -0,0 +1,52 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jovandeginste/spark-personal-assistant/pkg/ai"
+	"github.com/jovandeginste/spark-personal-assistant/pkg/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApp_UsageReport(t *testing.T) {
+	a := newTestApp(t)
+	a.Config.Assistant.Name = "Spark"
+	a.Config.Prices = []Price{
+		{Model: "gemini-test", Prompt: 0.10, Completion: 0.40},
+	}
+
+	ctx := a.TrackUsage(context.Background(), "print", "today")
+	ai.ReportUsage(ctx, ai.Usage{Provider: "gemini", Model: "gemini-test", PromptTokens: 1_000_000, CompletionTokens: 500_000})
+	ai.ReportUsage(ctx, ai.Usage{Provider: "gemini", Model: "gemini-test", PromptTokens: 1_000_000, CompletionTokens: 500_000})
+	ai.ReportUsage(a.TrackUsage(context.Background(), "chat", ""), ai.Usage{Provider: "ollama", Model: "llama3", PromptTokens: 10, CompletionTokens: 5})
+
+	r, err := a.UsageReport(time.Now().Add(-time.Hour), false)
+	require.NoError(t, err)
+
+	day := time.Now().In(data.LocalTimezone).Format("2006-01-02")
+
+	assert.Equal(t, data.UsageReport{
+		{Period: day, Command: "print", Format: "today", Provider: "gemini", Model: "gemini-test", Calls: 2, PromptTokens: 2_000_000, CompletionTokens: 1_000_000, Cost: 0.6, Priced: true},
+		{Period: day, Command: "chat", Provider: "ollama", Model: "llama3", Calls: 1, PromptTokens: 10, CompletionTokens: 5},
+	}, r)
+
+	r, err = a.UsageReport(time.Now().Add(time.Hour), true)
+	require.NoError(t, err)
+	assert.Empty(t, r, "Usage before the start should not be included")
+}
+
+func TestApp_TrackUsage_AsOf(t *testing.T) {
+	a := newTestApp(t)
+	a.clock = data.FixedClock(time.Date(2025, time.July, 10, 12, 0, 0, 0, data.LocalTimezone))
+
+	ai.ReportUsage(a.TrackUsage(context.Background(), "print", "today"), ai.Usage{Provider: "ollama", Model: "llama3", PromptTokens: 10, CompletionTokens: 5})
+
+	r, err := a.UsageReport(time.Date(2025, time.July, 1, 0, 0, 0, 0, data.LocalTimezone), false)
+	require.NoError(t, err)
+
+	require.Len(t, r, 1, "Usage should be recorded at the time of the clock")
+	assert.Equal(t, "2025-07-10", r[0].Period)
+}
@@ -0,0 +1,46 @@
+package data
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aquasecurity/table"
+)
+
+// CachedResponse is a response of the LLM, keyed by a hash of the provider,
+// model, persona and the full prompt
+type CachedResponse struct {
+	ID        uint64 `gorm:"primaryKey"`
+	Hash      string `gorm:"not null;uniqueIndex"`
+	Provider  string `gorm:"not null"`
+	Model     string `gorm:"not null"`
+	Persona   string `gorm:"not null"`
+	Response  string `gorm:"not null"`
+	Hits      uint64 `gorm:"not null;default:0"`
+	Misses    uint64 `gorm:"not null;default:0"`
+	CreatedAt time.Time
+}
+
+type CacheStats struct {
+	Responses int64
+	Expired   int64
+	Hits      uint64
+	Misses    uint64
+}
+
+// CacheHash returns the key of the response to a prompt
+func CacheHash(provider, model, persona string, prompt []string) string {
+	return generateHash(strings.Join(append([]string{provider, model, persona}, prompt...), "\n"))
+}
+
+func (s CacheStats) PrintTo(w io.Writer) {
+	t := table.New(w)
+	defer t.Render()
+
+	t.AddRow("Cached responses", strconv.FormatInt(s.Responses, 10))
+	t.AddRow("Expired responses", strconv.FormatInt(s.Expired, 10))
+	t.AddRow("Hits", strconv.FormatUint(s.Hits, 10))
+	t.AddRow("Misses", strconv.FormatUint(s.Misses, 10))
+}
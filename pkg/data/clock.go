@@ -0,0 +1,55 @@
+package data
+
+import (
+	"fmt"
+	"time"
+)
+
+// Clock tells the current time. Use another clock to run Spark as of another
+// day, or to make tests independent of the wall clock
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock is the wall clock
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// FixedClock always returns the same time
+type FixedClock time.Time
+
+func (c FixedClock) Now() time.Time {
+	return time.Time(c)
+}
+
+// OffsetClock is the wall clock moved by a fixed duration, so time keeps
+// passing during long-running processes
+type OffsetClock time.Duration
+
+func (c OffsetClock) Now() time.Time {
+	return time.Now().Add(time.Duration(c))
+}
+
+// TodayOf returns midnight of the current day of the clock, in LocalTimezone
+func TodayOf(c Clock) time.Time {
+	return startOfDay(c.Now())
+}
+
+// AsOf returns a clock that runs on the given day, formatted as YYYY-MM-DD,
+// at the current time of day
+func AsOf(day string) (Clock, error) {
+	d, err := time.ParseInLocation("2006-01-02", day, LocalTimezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date %q, use YYYY-MM-DD: %w", day, err)
+	}
+
+	return OffsetClock(d.Sub(startOfDay(time.Now()))), nil
+}
+
+func startOfDay(t time.Time) time.Time {
+	t = t.In(LocalTimezone)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, LocalTimezone)
+}
@@ -0,0 +1,34 @@
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFixedClock(t *testing.T) {
+	now := time.Date(2025, time.March, 3, 15, 4, 5, 0, LocalTimezone)
+	c := FixedClock(now)
+
+	assert.Equal(t, now, c.Now())
+	assert.Equal(t, time.Date(2025, time.March, 3, 0, 0, 0, 0, LocalTimezone), TodayOf(c))
+}
+
+func TestAsOf(t *testing.T) {
+	c, err := AsOf("2031-07-14")
+	require.NoError(t, err)
+
+	assert.Equal(t, "2031-07-14", c.Now().In(LocalTimezone).Format("2006-01-02"))
+	assert.Equal(t, time.Date(2031, time.July, 14, 0, 0, 0, 0, LocalTimezone), TodayOf(c))
+
+	// The time of day is kept
+	assert.InDelta(t, time.Since(startOfDay(time.Now())).Seconds(), c.Now().Sub(TodayOf(c)).Seconds(), 5)
+}
+
+func TestAsOf_Invalid(t *testing.T) {
+	_, err := AsOf("14/07/2031")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "YYYY-MM-DD")
+}
@@ -0,0 +1,499 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidDate = errors.New("invalid date")
+
+// explicitLayouts are the layouts of dates and times that are tried first;
+// the layouts with a zone are parsed as is, the others in the local timezone
+var explicitLayouts = []struct {
+	layout string
+	allDay bool
+	zoned  bool
+}{
+	{layout: time.RFC3339, zoned: true},
+	{layout: "2006-01-02T15:04Z07:00", zoned: true},
+	{layout: "2006-01-02 15:04:05Z07:00", zoned: true},
+	{layout: "2006-01-02 15:04Z07:00", zoned: true},
+	{layout: "2006-01-02 15:04:05 -0700", zoned: true},
+	{layout: "2006-01-02 15:04 -0700", zoned: true},
+	{layout: "2006-01-02T15:04:05"},
+	{layout: "2006-01-02T15:04"},
+	{layout: "2006-01-02 15:04:05"},
+	{layout: "2006-01-02 15:04"},
+	{layout: "2006-01-02", allDay: true},
+}
+
+var (
+	clockTime  = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(am|pm)?$`)
+	dayOfMonth = regexp.MustCompile(`^(\d{1,2})(?:st|nd|rd|th)?$`)
+	year       = regexp.MustCompile(`^\d{4}$`)
+)
+
+var ordinals = map[string]int{
+	"first": 1, "1st": 1, "second": 2, "2nd": 2, "third": 3, "3rd": 3,
+	"fourth": 4, "4th": 4, "fifth": 5, "5th": 5, "last": -1,
+}
+
+var numbers = map[string]int{
+	"a": 1, "an": 1, "one": 1, "two": 2, "three": 3, "four": 4, "five": 5,
+	"six": 6, "seven": 7, "eight": 8, "nine": 9, "ten": 10, "twelve": 12,
+}
+
+// ParseDateTime parses a date with an optional time, relative to now: an
+// explicit date like 2025-06-03, 2025-06-03 15:30 or 2025-06-03T15:30:00+02:00,
+// or an English expression like tomorrow, next friday 15:30, in 3 weeks,
+// 2 days ago, june 3 at 3pm or first monday of june. A trailing timezone name,
+// like Europe/Brussels or UTC, applies to the whole expression. It returns
+// whether the result is a day without a time.
+func ParseDateTime(s string, now time.Time) (time.Time, bool, error) {
+	s = strings.TrimSpace(s)
+	loc := LocalTimezone
+
+	if i := strings.LastIndexByte(s, ' '); i > 0 {
+		if l, ok := parseZone(s[i+1:]); ok {
+			s, loc = strings.TrimSpace(s[:i]), l
+		}
+	}
+
+	for _, l := range explicitLayouts {
+		var (
+			t   time.Time
+			err error
+		)
+
+		if l.zoned {
+			t, err = time.Parse(l.layout, s)
+		} else {
+			t, err = time.ParseInLocation(l.layout, s, loc)
+		}
+
+		if err == nil {
+			return t, l.allDay, nil
+		}
+	}
+
+	t, allDay, ok := parseNatural(strings.ToLower(s), now.In(loc))
+	if !ok {
+		return time.Time{}, false, fmt.Errorf("%w: %q", ErrInvalidDate, s)
+	}
+
+	return t, allDay, nil
+}
+
+func parseZone(s string) (*time.Location, bool) {
+	if s != "UTC" && !strings.Contains(s, "/") {
+		return nil, false
+	}
+
+	loc, err := time.LoadLocation(s)
+
+	return loc, err == nil
+}
+
+// parseNatural parses an English expression of a day and an optional time
+func parseNatural(s string, now time.Time) (time.Time, bool, bool) {
+	words := strings.Fields(strings.ReplaceAll(s, ",", " "))
+
+	words, hour, minute, hasTime, ok := extractTime(words)
+	if !ok {
+		return time.Time{}, false, false
+	}
+
+	if !hasTime {
+		if t, ok := parseDuration(words, now); ok {
+			return t, false, true
+		}
+	}
+
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	if len(words) > 0 {
+		if day, ok = parseDay(words, day); !ok {
+			return time.Time{}, false, false
+		}
+	} else if !hasTime {
+		return time.Time{}, false, false
+	}
+
+	if !hasTime {
+		return day, true, true
+	}
+
+	y, m, d := day.Date()
+
+	return time.Date(y, m, d, hour, minute, 0, 0, day.Location()), false, true
+}
+
+// extractTime removes the time of day from the words, like 15:30, 3pm,
+// 3:30 pm, noon or midnight, optionally after "at"
+func extractTime(words []string) ([]string, int, int, bool, bool) {
+	var (
+		rest         []string
+		hour, minute int
+		found        bool
+	)
+
+	for i := 0; i < len(words); i++ {
+		w := words[i]
+
+		if w == "at" {
+			continue
+		}
+
+		if i+1 < len(words) && (words[i+1] == "am" || words[i+1] == "pm") {
+			w += words[i+1]
+			i++
+		}
+
+		h, m, ok := parseClock(w)
+		if !ok {
+			rest = append(rest, w)
+			continue
+		}
+
+		if found {
+			return nil, 0, 0, false, false
+		}
+
+		hour, minute, found = h, m, true
+	}
+
+	return rest, hour, minute, found, true
+}
+
+func parseClock(w string) (int, int, bool) {
+	switch w {
+	case "noon":
+		return 12, 0, true
+	case "midnight":
+		return 0, 0, true
+	}
+
+	m := clockTime.FindStringSubmatch(w)
+	if m == nil || (m[2] == "" && m[3] == "") {
+		return 0, 0, false
+	}
+
+	h, _ := strconv.Atoi(m[1])
+	minute, _ := strconv.Atoi(m[2])
+
+	switch m[3] {
+	case "am", "pm":
+		if h < 1 || h > 12 {
+			return 0, 0, false
+		}
+
+		h %= 12
+		if m[3] == "pm" {
+			h += 12
+		}
+	}
+
+	if h > 23 || minute > 59 {
+		return 0, 0, false
+	}
+
+	return h, minute, true
+}
+
+// parseDuration parses "in 2 hours" and "30 minutes ago", which are a
+// moment rather than a day
+func parseDuration(words []string, now time.Time) (time.Time, bool) {
+	n, unit, ok := parseOffset(words)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	switch unit {
+	case "minute":
+		return now.Add(time.Duration(n) * time.Minute).Truncate(time.Minute), true
+	case "hour":
+		return now.Add(time.Duration(n) * time.Hour).Truncate(time.Minute), true
+	}
+
+	return time.Time{}, false
+}
+
+// parseOffset parses "in 3 weeks", "in a month" and "2 days ago" as a
+// number of units
+func parseOffset(words []string) (int, string, bool) {
+	sign := 1
+
+	switch {
+	case len(words) == 3 && words[0] == "in":
+		words = words[1:]
+	case len(words) == 3 && words[2] == "ago":
+		words, sign = words[:2], -1
+	default:
+		return 0, "", false
+	}
+
+	n, ok := numbers[words[0]]
+	if !ok {
+		var err error
+
+		if n, err = strconv.Atoi(words[0]); err != nil {
+			return 0, "", false
+		}
+	}
+
+	unit := strings.TrimSuffix(words[1], "s")
+
+	switch unit {
+	case "minute", "hour", "day", "week", "month", "year":
+		return sign * n, unit, true
+	}
+
+	return 0, "", false
+}
+
+// parseDay parses the words as a day, relative to today
+func parseDay(words []string, today time.Time) (time.Time, bool) {
+	switch strings.Join(words, " ") {
+	case "today", "tonight":
+		return today, true
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), true
+	case "yesterday":
+		return today.AddDate(0, 0, -1), true
+	case "day after tomorrow", "the day after tomorrow":
+		return today.AddDate(0, 0, 2), true
+	case "next week":
+		return today.AddDate(0, 0, 7), true
+	case "last week":
+		return today.AddDate(0, 0, -7), true
+	case "next month":
+		return addMonths(today, 1), true
+	case "last month":
+		return addMonths(today, -1), true
+	case "next year":
+		return addMonths(today, 12), true
+	case "last year":
+		return addMonths(today, -12), true
+	}
+
+	if n, unit, ok := parseOffset(words); ok {
+		switch unit {
+		case "day":
+			return today.AddDate(0, 0, n), true
+		case "week":
+			return today.AddDate(0, 0, 7*n), true
+		case "month":
+			return addMonths(today, n), true
+		case "year":
+			return addMonths(today, 12*n), true
+		}
+
+		return time.Time{}, false
+	}
+
+	if t, ok := parseWeekday(words, today); ok {
+		return t, true
+	}
+
+	if t, ok := parseNthWeekday(words, today); ok {
+		return t, true
+	}
+
+	return parseMonthDay(words, today)
+}
+
+// parseWeekday parses "friday" and "this friday" as the first friday from
+// today on, "next friday" as the friday of next week, which starts on Monday,
+// and "last friday" as the last one before today
+func parseWeekday(words []string, today time.Time) (time.Time, bool) {
+	prefix := ""
+	if len(words) == 2 {
+		prefix, words = words[0], words[1:]
+	}
+
+	wd, ok := parseWeekdayName(words[0])
+	if len(words) != 1 || !ok {
+		return time.Time{}, false
+	}
+
+	days := (int(wd) - int(today.Weekday()) + 7) % 7
+
+	switch prefix {
+	case "", "this", "on":
+	case "next":
+		monday := 7 - (int(today.Weekday())+6)%7
+		days = monday + (int(wd)+6)%7
+	case "last":
+		days -= 7
+	default:
+		return time.Time{}, false
+	}
+
+	return today.AddDate(0, 0, days), true
+}
+
+// parseNthWeekday parses "first monday of june [2026]", "last friday of next
+// month" and "2nd tuesday in march"; a month without a year is the next one
+// that has the day
+func parseNthWeekday(words []string, today time.Time) (time.Time, bool) {
+	if len(words) < 4 || (words[2] != "of" && words[2] != "in") {
+		return time.Time{}, false
+	}
+
+	n, ok := ordinals[words[0]]
+	if !ok {
+		return time.Time{}, false
+	}
+
+	wd, ok := parseWeekdayName(words[1])
+	if !ok {
+		return time.Time{}, false
+	}
+
+	nth := func(y int, m time.Month) (time.Time, bool) {
+		first := time.Date(y, m, 1, 0, 0, 0, 0, today.Location())
+		days := weekdaysIn(first, first.AddDate(0, 1, -1).Day(), RRuleDay{N: n, Weekday: wd})
+
+		if len(days) == 0 {
+			return time.Time{}, false
+		}
+
+		return days[0], true
+	}
+
+	switch strings.Join(words[3:], " ") {
+	case "this month":
+		return nth(today.Year(), today.Month())
+	case "next month":
+		next := addMonths(today, 1)
+		return nth(next.Year(), next.Month())
+	}
+
+	m, y, hasYear, ok := parseMonthYear(words[3:])
+	if !ok {
+		return time.Time{}, false
+	}
+
+	if hasYear {
+		return nth(y, m)
+	}
+
+	for y := today.Year(); y <= today.Year()+1; y++ {
+		if t, ok := nth(y, m); ok && !t.Before(today) {
+			return t, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// parseMonthDay parses "june 3", "3 june", "june 3rd 2026" and "3 jun 2026";
+// a day without a year is the next one
+func parseMonthDay(words []string, today time.Time) (time.Time, bool) {
+	if len(words) < 2 || len(words) > 3 {
+		return time.Time{}, false
+	}
+
+	words = slices.Clone(words)
+
+	if _, ok := parseMonth(words[0]); ok {
+		words[0], words[1] = words[1], words[0]
+	}
+
+	d := dayOfMonth.FindStringSubmatch(words[0])
+	if d == nil {
+		return time.Time{}, false
+	}
+
+	day, _ := strconv.Atoi(d[1])
+
+	m, y, hasYear, ok := parseMonthYear(words[1:])
+	if !ok {
+		return time.Time{}, false
+	}
+
+	date := func(y int) (time.Time, bool) {
+		t := time.Date(y, m, day, 0, 0, 0, 0, today.Location())
+
+		return t, t.Month() == m && t.Day() == day
+	}
+
+	if hasYear {
+		return date(y)
+	}
+
+	// February 29 is at most 4 years away
+	for y := today.Year(); y <= today.Year()+4; y++ {
+		if t, ok := date(y); ok && !t.Before(today) {
+			return t, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+func parseMonthYear(words []string) (time.Month, int, bool, bool) {
+	m, ok := parseMonth(words[0])
+	if !ok || len(words) > 2 {
+		return 0, 0, false, false
+	}
+
+	if len(words) == 1 {
+		return m, 0, false, true
+	}
+
+	if !year.MatchString(words[1]) {
+		return 0, 0, false, false
+	}
+
+	y, _ := strconv.Atoi(words[1])
+
+	return m, y, true, true
+}
+
+func parseWeekdayName(s string) (time.Weekday, bool) {
+	if len(s) < 3 {
+		return 0, false
+	}
+
+	for wd := time.Sunday; wd <= time.Saturday; wd++ {
+		if strings.HasPrefix(strings.ToLower(wd.String()), s) {
+			return wd, true
+		}
+	}
+
+	return 0, false
+}
+
+func parseMonth(s string) (time.Month, bool) {
+	if len(s) < 3 {
+		return 0, false
+	}
+
+	for m := time.January; m <= time.December; m++ {
+		name := strings.ToLower(m.String())
+		if s == name || (strings.HasPrefix(name, s) && len(s) <= 4) {
+			return m, true
+		}
+	}
+
+	return 0, false
+}
+
+// addMonths adds months to the day; the day is clamped to the end of shorter
+// months, so a month after January 31 is the end of February
+func addMonths(t time.Time, n int) time.Time {
+	y, m, d := t.Date()
+	first := time.Date(y, m+time.Month(n), 1, t.Hour(), t.Minute(), t.Second(), 0, t.Location())
+
+	if last := first.AddDate(0, 1, -1).Day(); d > last {
+		d = last
+	}
+
+	return first.AddDate(0, 0, d-1)
+}
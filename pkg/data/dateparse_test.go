@@ -0,0 +1,128 @@
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDateTime(t *testing.T) {
+	LocalTimezone = time.UTC
+	defer func() { LocalTimezone = time.Local }()
+
+	// A Wednesday
+	now := time.Date(2025, time.July, 9, 10, 15, 30, 0, time.UTC)
+
+	brussels, err := time.LoadLocation("Europe/Brussels")
+	require.NoError(t, err)
+
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+	at := func(y int, m time.Month, d, h, min int) time.Time { return time.Date(y, m, d, h, min, 0, 0, time.UTC) }
+
+	tests := []struct {
+		input    string
+		expected time.Time
+		allDay   bool
+	}{
+		{"2025-08-01", day(2025, time.August, 1), true},
+		{"2025-08-01 09:30", at(2025, time.August, 1, 9, 30), false},
+		{"2025-08-01T09:30:00+02:00", time.Date(2025, time.August, 1, 7, 30, 0, 0, time.UTC), false},
+		{"2025-08-01 09:30 America/New_York", time.Date(2025, time.August, 1, 9, 30, 0, 0, newYork), false},
+		{"today", day(2025, time.July, 9), true},
+		{"Tomorrow", day(2025, time.July, 10), true},
+		{"yesterday", day(2025, time.July, 8), true},
+		{"the day after tomorrow", day(2025, time.July, 11), true},
+		{"tomorrow at 3pm", at(2025, time.July, 10, 15, 0), false},
+		{"3:30 pm tomorrow", at(2025, time.July, 10, 15, 30), false},
+		{"tomorrow 9:00 Europe/Brussels", time.Date(2025, time.July, 10, 9, 0, 0, 0, brussels), false},
+		{"15:30", at(2025, time.July, 9, 15, 30), false},
+		{"noon", at(2025, time.July, 9, 12, 0), false},
+		{"12am", at(2025, time.July, 9, 0, 0), false},
+		{"friday", day(2025, time.July, 11), true},
+		{"wednesday", day(2025, time.July, 9), true},
+		{"next friday 15:30", at(2025, time.July, 18, 15, 30), false},
+		{"next wednesday", day(2025, time.July, 16), true},
+		{"next monday", day(2025, time.July, 14), true},
+		{"next sunday", day(2025, time.July, 20), true},
+		{"on tue", day(2025, time.July, 15), true},
+		{"last wednesday", day(2025, time.July, 2), true},
+		{"in 3 weeks", day(2025, time.July, 30), true},
+		{"in a month", day(2025, time.August, 9), true},
+		{"in two years", day(2027, time.July, 9), true},
+		{"2 days ago", day(2025, time.July, 7), true},
+		{"next week", day(2025, time.July, 16), true},
+		{"in 2 hours", at(2025, time.July, 9, 12, 15), false},
+		{"30 minutes ago", at(2025, time.July, 9, 9, 45), false},
+		{"first monday of june", day(2026, time.June, 1), true},
+		{"first monday of june 2025", day(2025, time.June, 2), true},
+		{"last friday of next month", day(2025, time.August, 29), true},
+		{"2nd tuesday in july", day(2026, time.July, 14), true},
+		{"2nd tuesday of this month", day(2025, time.July, 8), true},
+		{"june 3", day(2026, time.June, 3), true},
+		{"3 Dec", day(2025, time.December, 3), true},
+		{"July 9th at 18:00", at(2025, time.July, 9, 18, 0), false},
+		{"feb 29", day(2028, time.February, 29), true},
+		{"september 1, 2030", day(2030, time.September, 1), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, allDay, err := ParseDateTime(tt.input, now)
+			require.NoError(t, err)
+
+			assert.True(t, tt.expected.Equal(got), "expected %s, got %s", tt.expected, got)
+			assert.Equal(t, tt.allDay, allDay)
+		})
+	}
+}
+
+func TestParseDateTime_Errors(t *testing.T) {
+	now := time.Date(2025, time.July, 9, 10, 15, 30, 0, LocalTimezone)
+
+	for _, s := range []string{
+		"",
+		"someday",
+		"10/26/2023",
+		"2025-02-30",
+		"feb 30 2025",
+		"13pm",
+		"friday 10:00 11:00",
+		"in 3 fortnights",
+		"in 2 hours at 15:00",
+		"fifth friday of june 2025",
+		"next",
+	} {
+		_, _, err := ParseDateTime(s, now)
+		require.ErrorIs(t, err, ErrInvalidDate, s)
+	}
+}
+
+func Test_addMonths(t *testing.T) {
+	jan31 := time.Date(2025, time.January, 31, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, time.Date(2025, time.February, 28, 0, 0, 0, 0, time.UTC), addMonths(jan31, 1))
+	assert.Equal(t, time.Date(2024, time.December, 31, 0, 0, 0, 0, time.UTC), addMonths(jan31, -1))
+	assert.Equal(t, time.Date(2026, time.January, 31, 0, 0, 0, 0, time.UTC), addMonths(jan31, 12))
+}
+
+func TestEntry_SetDate_Natural(t *testing.T) {
+	now := time.Date(2025, time.July, 9, 10, 15, 0, 0, LocalTimezone)
+
+	e := &Entry{}
+
+	require.NoError(t, e.SetDate("next friday 15:30", now))
+	assert.Equal(t, time.Date(2025, time.July, 18, 15, 30, 0, 0, LocalTimezone), e.Date.Time)
+	assert.False(t, e.AllDay, "An entry with a time does not last all day")
+
+	require.NoError(t, e.SetDate("tomorrow", now))
+	assert.Equal(t, time.Date(2025, time.July, 10, 0, 0, 0, 0, LocalTimezone), e.Date.Time)
+	assert.True(t, e.AllDay)
+
+	require.NoError(t, e.SetDue("in 2 weeks", now))
+	assert.Equal(t, "2025-07-23", e.FormattedDue())
+}
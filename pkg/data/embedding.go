@@ -0,0 +1,121 @@
+package data
+
+import (
+	"cmp"
+	"database/sql/driver"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Embedding is the vector of an entry, computed by an embedding model
+type Embedding struct {
+	EntryID   uint64 `gorm:"primaryKey;autoIncrement:false"`
+	Model     string `gorm:"primaryKey"`
+	Hash      string `gorm:"not null"` // of the embedded text, to detect changes
+	Vector    Vector `gorm:"not null"`
+	CreatedAt time.Time
+}
+
+// Vector is stored as little-endian float32 values
+type Vector []float32
+
+func (v Vector) Value() (driver.Value, error) {
+	b := make([]byte, 4*len(v))
+
+	for i, x := range v {
+		binary.LittleEndian.PutUint32(b[4*i:], math.Float32bits(x))
+	}
+
+	return b, nil
+}
+
+func (v *Vector) Scan(value any) error {
+	b, ok := value.([]byte)
+	if !ok || len(b)%4 != 0 {
+		return fmt.Errorf("cannot scan type %T into Vector", value)
+	}
+
+	*v = make(Vector, len(b)/4)
+
+	for i := range *v {
+		(*v)[i] = math.Float32frombits(binary.LittleEndian.Uint32(b[4*i:]))
+	}
+
+	return nil
+}
+
+// Similarity is the cosine similarity of two vectors, between -1 and 1
+func (v Vector) Similarity(o Vector) float64 {
+	if len(v) != len(o) {
+		return 0
+	}
+
+	var dot, nv, no float64
+
+	for i := range v {
+		dot += float64(v[i]) * float64(o[i])
+		nv += float64(v[i]) * float64(v[i])
+		no += float64(o[i]) * float64(o[i])
+	}
+
+	if nv == 0 || no == 0 {
+		return 0
+	}
+
+	return dot / math.Sqrt(nv*no)
+}
+
+// Match is an entry with its similarity to a query
+type Match struct {
+	EntryID    uint64
+	Similarity float64
+}
+
+// TopMatches returns the k embeddings that are most similar to the query,
+// most similar first; unrelated embeddings are skipped
+func TopMatches(query Vector, embeddings []Embedding, k int) []Match {
+	matches := make([]Match, 0, len(embeddings))
+
+	for _, e := range embeddings {
+		s := query.Similarity(e.Vector)
+		if s <= 0 {
+			continue
+		}
+
+		matches = append(matches, Match{EntryID: e.EntryID, Similarity: s})
+	}
+
+	slices.SortStableFunc(matches, func(a, b Match) int {
+		return cmp.Compare(b.Similarity, a.Similarity)
+	})
+
+	return matches[:min(k, len(matches))]
+}
+
+// EmbeddingText is the text of the entry that is embedded: the date, summary,
+// tags and metadata
+func (e *Entry) EmbeddingText() string {
+	parts := []string{e.Date.In(LocalTimezone).Format("2006-01-02"), e.Summary}
+
+	if len(e.Tags) > 0 {
+		parts = append(parts, "Tags: "+strings.Join(e.TagNames(), ", "))
+	}
+
+	if len(e.Metadata) > 0 {
+		if j, err := json.Marshal(e.Metadata); err == nil {
+			parts = append(parts, string(j))
+		}
+	}
+
+	return strings.Join(parts, "\n")
+}
+
+// EmbeddingHash detects changes to the embedded text of the entry
+func (e *Entry) EmbeddingHash() string {
+	return generateHash(e.EmbeddingText())
+}
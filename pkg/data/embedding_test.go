@@ -0,0 +1,62 @@
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVector_ValueScan(t *testing.T) {
+	v := Vector{0.5, -1, 3.25}
+
+	b, err := v.Value()
+	require.NoError(t, err)
+
+	var scanned Vector
+	require.NoError(t, scanned.Scan(b))
+	assert.Equal(t, v, scanned)
+
+	assert.Error(t, scanned.Scan("not bytes"))
+	assert.Error(t, scanned.Scan([]byte{1, 2, 3}))
+}
+
+func TestVector_Similarity(t *testing.T) {
+	assert.InDelta(t, 1, Vector{1, 2}.Similarity(Vector{2, 4}), 1e-9)
+	assert.InDelta(t, 0, Vector{1, 0}.Similarity(Vector{0, 1}), 1e-9)
+	assert.InDelta(t, -1, Vector{1, 0}.Similarity(Vector{-1, 0}), 1e-9)
+	assert.Zero(t, Vector{1, 0}.Similarity(Vector{1, 0, 0}), "Vectors of different models can not be compared")
+	assert.Zero(t, Vector{0, 0}.Similarity(Vector{1, 0}))
+}
+
+func TestTopMatches(t *testing.T) {
+	embeddings := []Embedding{
+		{EntryID: 1, Vector: Vector{0, 1}},
+		{EntryID: 2, Vector: Vector{1, 0.1}},
+		{EntryID: 3, Vector: Vector{1, 1}},
+		{EntryID: 4, Vector: Vector{-1, 0}},
+	}
+
+	matches := TopMatches(Vector{1, 0}, embeddings, 2)
+	require.Len(t, matches, 2)
+	assert.Equal(t, uint64(2), matches[0].EntryID)
+	assert.Equal(t, uint64(3), matches[1].EntryID)
+
+	assert.Len(t, TopMatches(Vector{1, 0}, embeddings, 10), 2, "Unrelated entries should be skipped")
+}
+
+func TestEntry_EmbeddingText(t *testing.T) {
+	e := Entry{
+		Date:    HumanTime{time.Date(2025, time.May, 2, 9, 0, 0, 0, LocalTimezone)},
+		Summary: "School trip",
+	}
+
+	assert.Equal(t, "2025-05-02\nSchool trip", e.EmbeddingText())
+
+	hash := e.EmbeddingHash()
+	e.SetMetadata("Location", "Zoo")
+
+	assert.Equal(t, "2025-05-02\nSchool trip\n{\"Location\":\"Zoo\"}", e.EmbeddingText())
+	assert.NotEqual(t, hash, e.EmbeddingHash(), "Metadata changes should be detected")
+}
@@ -3,6 +3,7 @@ package data
 import (
 	"crypto/sha512"
 	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -25,17 +26,29 @@ const (
 )
 
 type Entry struct {
-	ID         uint64         `gorm:"primaryKey" json:"-"`
+	ID         uint64         `gorm:"primaryKey" json:",omitempty"`
 	RemoteID   string         `gorm:"not null;uniqueIndex:idx_source_id" json:"-"`
 	Date       HumanTime      `gorm:"not null;index"`
+	End        *HumanTime     `gorm:"column:ends_at;index" json:",omitempty"` // exclusive, like in iCalendar
+	AllDay     bool           `gorm:"not null;default:false" json:",omitempty"`
+	Repeat     string         `gorm:"not null;default:''" json:",omitempty"` // an RRULE, see RRule
+	Exceptions []string       `gorm:"serializer:json" json:"-"`              // dates without an occurrence
 	Importance Importance     `gorm:"not null" json:",omitempty"`
 	SourceID   uint64         `gorm:"not null;uniqueIndex:idx_source_id" json:"-"`
 	Summary    string         `gorm:"not null"`
 	Metadata   map[string]any `gorm:"serializer:json" json:",omitempty"`
+	EditedAt   *time.Time     `json:"-"`                               // set when edited by hand; imports keep the edits
+	Moved      bool           `gorm:"not null;default:false" json:"-"` // moved to another source by hand
+
+	// Tasks have a status, see IsTask
+	Status      TaskStatus `gorm:"not null;default:'';index" json:",omitempty"`
+	Due         *HumanTime `gorm:"column:due_at;index" json:",omitempty"`
+	CompletedAt *time.Time `json:",omitempty"`
 
 	DateString string `gorm:"-" json:"-"`
 
 	Source *Source `json:",omitempty"`
+	Tags   []Tag   `gorm:"many2many:entry_tags" json:",omitempty"`
 }
 
 func (e *Entry) SetMetadata(key string, value any) {
@@ -46,6 +59,15 @@ func (e *Entry) SetMetadata(key string, value any) {
 	e.Metadata[key] = value
 }
 
+// UnsetMetadata removes the key from the metadata
+func (e *Entry) UnsetMetadata(key string) {
+	delete(e.Metadata, key)
+
+	if len(e.Metadata) == 0 {
+		e.Metadata = nil
+	}
+}
+
 func (e *Entry) SetMetadataIfNotEmpty(key string, value any) {
 	switch v := value.(type) {
 	case string:
@@ -100,25 +122,126 @@ func generateHash(s string) string {
 	return base64.URLEncoding.EncodeToString(hasher.Sum(nil))
 }
 
+// FormattedDate formats the start of the entry, without a time if it lasts
+// all day
 func (e *Entry) FormattedDate() string {
-	return e.Date.FormatDate()
+	return e.Date.formatAs(e.AllDay, LocalLocale.TimeLayout())
+}
+
+// FormattedEnd formats the end of the entry; the end of an entry that lasts
+// all day is formatted as its last day. It is empty if the entry has no end,
+// or ends the day it starts.
+func (e *Entry) FormattedEnd() string {
+	return e.formatEnd(LocalLocale.TimeLayout())
+}
+
+func (e *Entry) formatEnd(timeLayout string) string {
+	if e.End == nil || e.End.IsZero() {
+		return ""
+	}
+
+	if !e.AllDay {
+		return e.End.formatAs(false, timeLayout)
+	}
+
+	last := HumanTime{e.End.AddDate(0, 0, -1)}
+	if !last.After(e.Date.Time) {
+		return ""
+	}
+
+	return last.formatAs(true, timeLayout)
 }
 
-func parseDate(d string) (time.Time, error) {
+// Duration is the time between the start and the end of the entry, or zero if
+// it has no end
+func (e *Entry) Duration() time.Duration {
+	if e.End == nil || e.End.IsZero() {
+		return 0
+	}
+
+	return e.End.Sub(e.Date.Time)
+}
+
+// MarshalJSON formats the dates of the entry like FormattedDate and
+// FormattedEnd, in 24 hour notation so they can be parsed again
+func (e Entry) MarshalJSON() ([]byte, error) {
+	type entry Entry
+
+	return json.Marshal(struct {
+		entry
+		Date string
+		End  string   `json:",omitempty"`
+		Due  string   `json:",omitempty"`
+		Tags []string `json:",omitempty"`
+	}{
+		entry: entry(e),
+		Date:  e.Date.formatAs(e.AllDay, "15:04"),
+		End:   e.formatEnd("15:04"),
+		Due:   e.FormattedDue(),
+		Tags:  e.TagNames(),
+	})
+}
+
+// parseDateTime parses a date with an optional time relative to now, see
+// ParseDateTime; an empty date is today
+func parseDateTime(d string, now time.Time) (time.Time, bool, error) {
 	if d == "" {
-		return time.Now().In(LocalTimezone).Truncate(24 * time.Hour), nil
+		return startOfDay(now), true, nil
 	}
 
-	return time.ParseInLocation("2006-01-02", d, LocalTimezone)
+	return ParseDateTime(d, now)
 }
 
-func (e *Entry) SetDate(d string) error {
-	parsedDate, err := parseDate(d)
+// parseDate parses a date like parseDateTime, without the time
+func parseDate(d string, now time.Time) (time.Time, error) {
+	t, _, err := parseDateTime(d, now)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return startOfDay(t), nil
+}
+
+// SetDate sets the date of the entry, and its time if d has one, relative to
+// now, see ParseDateTime
+func (e *Entry) SetDate(d string, now time.Time) error {
+	parsedDate, allDay, err := parseDateTime(d, now)
 	if err != nil {
 		return err
 	}
 
 	e.Date = HumanTime{parsedDate}
+	e.AllDay = allDay
+	e.DateString = e.FormattedDate()
+
+	return nil
+}
+
+// MoveTo moves the entry to another date relative to now, see ParseDateTime;
+// it keeps its duration, and its time of day unless d has a time
+func (e *Entry) MoveTo(d string, now time.Time) error {
+	t, allDay, err := parseDateTime(d, now)
+	if err != nil {
+		return err
+	}
+
+	duration := e.Duration()
+
+	if allDay {
+		start := e.Date.In(LocalTimezone)
+		y, m, dd := t.Date()
+		t = time.Date(y, m, dd, start.Hour(), start.Minute(), start.Second(), 0, LocalTimezone)
+	} else {
+		e.AllDay = false
+	}
+
+	e.Date = HumanTime{t}
+
+	if e.End != nil {
+		e.End = &HumanTime{e.Date.Add(duration)}
+	}
+
+	e.DateString = e.FormattedDate()
 
 	return nil
 }
@@ -144,13 +267,46 @@ func (e *Entry) PrintTo(w io.Writer) {
 	t.AddRow("ID", strconv.FormatUint(e.ID, 10))
 	t.AddRow("Remote ID", e.RemoteID)
 	t.AddRow("Data", e.DateString)
+
+	if end := e.FormattedEnd(); end != "" {
+		t.AddRow("End", end)
+	}
+
+	if e.Repeat != "" {
+		t.AddRow("Repeat", e.Repeat)
+	}
+
+	if len(e.Exceptions) > 0 {
+		t.AddRow("Exceptions", strings.Join(e.Exceptions, ", "))
+	}
+
 	t.AddRow("Summary", e.Summary)
 	t.AddRow("Importance", string(e.Importance))
 
+	if e.IsTask() {
+		t.AddRow("Status", string(e.Status))
+	}
+
+	if due := e.FormattedDue(); due != "" {
+		t.AddRow("Due", due)
+	}
+
+	if e.CompletedAt != nil {
+		t.AddRow("Completed", HumanTime{*e.CompletedAt}.formatAs(false, LocalLocale.TimeLayout()))
+	}
+
 	if e.Source != nil {
 		t.AddRow("Source", e.Source.Name)
 	}
 
+	if len(e.Tags) > 0 {
+		t.AddRow("Tags", strings.Join(e.TagNames(), ", "))
+	}
+
+	if e.EditedAt != nil {
+		t.AddRow("Edited", HumanTime{*e.EditedAt}.formatAs(false, LocalLocale.TimeLayout()))
+	}
+
 	for k, v := range e.Metadata {
 		t.AddRow(k, fmt.Sprintf("%v", v))
 	}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"crypto/sha512"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"testing"
 	"time"
@@ -261,7 +262,8 @@ func TestEntry_AfterFind(t *testing.T) {
 	expectedFormattedDate := "2023-11-15"
 
 	e := &Entry{
-		Date: HumanTime{testDate},
+		Date:   HumanTime{testDate},
+		AllDay: true,
 	}
 
 	err := e.AfterFind(nil) // Pass nil for DB as FormattedDate doesn't use it
@@ -326,7 +328,7 @@ func Test_parseDate(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			parsedTime, err := parseDate(tt.dateString)
+			parsedTime, err := parseDate(tt.dateString, time.Now())
 
 			if tt.expectError {
 				assert.Error(t, err, "Expected an error for invalid date string")
@@ -388,7 +390,7 @@ func TestEntry_SetDate(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			e := &Entry{}
-			err := e.SetDate(tt.dateString)
+			err := e.SetDate(tt.dateString, time.Now())
 
 			if tt.expectError {
 				assert.Error(t, err, "Expected an error for invalid date string")
@@ -408,6 +410,33 @@ func TestEntry_SetDate(t *testing.T) {
 	}
 }
 
+func TestEntry_MoveTo(t *testing.T) {
+	start := time.Date(2024, 1, 15, 22, 30, 0, 0, LocalTimezone)
+	e := &Entry{
+		Date: HumanTime{start},
+		End:  &HumanTime{start.Add(3 * time.Hour)},
+	}
+
+	require.NoError(t, e.MoveTo("2024-02-01", time.Now()))
+
+	assert.Equal(t, time.Date(2024, 2, 1, 22, 30, 0, 0, LocalTimezone), e.Date.Time, "The time of day should be kept")
+	assert.Equal(t, 3*time.Hour, e.Duration(), "The duration should be kept")
+	assert.Equal(t, e.FormattedDate(), e.DateString)
+
+	require.Error(t, e.MoveTo("someday", time.Now()))
+}
+
+func TestEntry_UnsetMetadata(t *testing.T) {
+	e := &Entry{Metadata: map[string]any{"a": 1, "b": 2}}
+
+	e.UnsetMetadata("a")
+	e.UnsetMetadata("missing")
+	assert.Equal(t, map[string]any{"b": 2}, e.Metadata)
+
+	e.UnsetMetadata("b")
+	assert.Nil(t, e.Metadata, "Metadata without keys should be nil")
+}
+
 func TestEntry_SetImportance(t *testing.T) {
 	tests := []struct {
 		name               string
@@ -583,3 +612,44 @@ func TestEntry_PrintTo(t *testing.T) {
 		})
 	}
 }
+
+func TestEntry_End(t *testing.T) {
+	midnight := time.Date(2025, time.July, 1, 0, 0, 0, 0, LocalTimezone)
+
+	t.Run("Event at midnight", func(t *testing.T) {
+		e := Entry{Date: HumanTime{midnight}, End: &HumanTime{midnight.Add(90 * time.Minute)}, Summary: "Night train"}
+
+		assert.Equal(t, "2025-07-01 00:00", e.FormattedDate(), "Midnight events should not be shown as all day")
+		assert.Equal(t, "2025-07-01 01:30", e.FormattedEnd())
+		assert.Equal(t, 90*time.Minute, e.Duration())
+
+		j, err := json.Marshal(e)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"Date":"2025-07-01 00:00","End":"2025-07-01 01:30","Summary":"Night train"}`, string(j))
+	})
+
+	t.Run("Holiday of a week", func(t *testing.T) {
+		e := Entry{Date: HumanTime{midnight}, End: &HumanTime{midnight.AddDate(0, 0, 7)}, AllDay: true, Summary: "Holiday"}
+
+		assert.Equal(t, "2025-07-01", e.FormattedDate())
+		assert.Equal(t, "2025-07-07", e.FormattedEnd(), "The end of all-day entries should be their last day")
+
+		j, err := json.Marshal(e)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"Date":"2025-07-01","End":"2025-07-07","AllDay":true,"Summary":"Holiday"}`, string(j))
+	})
+
+	t.Run("Single day", func(t *testing.T) {
+		e := Entry{Date: HumanTime{midnight}, End: &HumanTime{midnight.AddDate(0, 0, 1)}, AllDay: true}
+
+		assert.Empty(t, e.FormattedEnd(), "Entries that end the day they start have no end to show")
+		assert.Equal(t, 24*time.Hour, e.Duration())
+	})
+
+	t.Run("No end", func(t *testing.T) {
+		e := Entry{Date: HumanTime{midnight}}
+
+		assert.Empty(t, e.FormattedEnd())
+		assert.Zero(t, e.Duration())
+	})
+}
@@ -20,8 +20,9 @@ func (ct *HumanTime) MarshalJSON() ([]byte, error) {
 		return []byte("null"), nil
 	}
 
-	// Format the time using the custom layout
-	formatted := ct.FormatDate()
+	// Format the time using the custom layout; JSON is not localized, so it
+	// can be parsed again
+	formatted := ct.format("15:04")
 
 	// Return the formatted time as a JSON string (needs quotes)
 	// Using fmt.Sprintf is a common way to ensure it's quoted
@@ -108,10 +109,24 @@ func (ct *HumanTime) Scan(value any) error {
 	return fmt.Errorf("cannot scan type %T into HumanTime", value)
 }
 
+// FormatDate formats the date in the clock notation of LocalLocale, without
+// the time at midnight. Use Entry.FormattedDate for the date of an entry,
+// which knows whether it lasts all day.
 func (ct *HumanTime) FormatDate() string {
-	if ct.Hour() == 0 && ct.Minute() == 0 {
+	return ct.format(LocalLocale.TimeLayout())
+}
+
+// format guesses that a time at midnight is all day; only for times that are
+// not part of an entry
+func (ct *HumanTime) format(timeLayout string) string {
+	return ct.formatAs(ct.Hour() == 0 && ct.Minute() == 0, timeLayout)
+}
+
+// formatAs formats the date, and the time unless it is all day
+func (ct HumanTime) formatAs(allDay bool, timeLayout string) string {
+	if allDay {
 		return ct.In(LocalTimezone).Format("2006-01-02")
 	}
 
-	return ct.In(LocalTimezone).Format("2006-01-02 15:04")
+	return ct.In(LocalTimezone).Format("2006-01-02 " + timeLayout)
 }
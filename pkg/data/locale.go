@@ -0,0 +1,148 @@
+package data
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Locale determines the language and notations of summaries and local output
+type Locale struct {
+	Language     string `mapstructure:"language"`      // eg. English or Dutch
+	Units        string `mapstructure:"units"`         // metric or imperial
+	Clock        string `mapstructure:"clock"`         // 24h or 12h
+	FirstWeekday string `mapstructure:"first_weekday"` // eg. monday or sunday
+}
+
+var DefaultLocale = Locale{
+	Language:     "English",
+	Units:        "metric",
+	Clock:        "24h",
+	FirstWeekday: "monday",
+}
+
+// weekdayNames are the names of the weekdays, from Sunday on, in the languages
+// that local output is translated to; other languages use English
+var weekdayNames = map[string][7]string{
+	"dutch":      {"zondag", "maandag", "dinsdag", "woensdag", "donderdag", "vrijdag", "zaterdag"},
+	"french":     {"dimanche", "lundi", "mardi", "mercredi", "jeudi", "vendredi", "samedi"},
+	"german":     {"Sonntag", "Montag", "Dienstag", "Mittwoch", "Donnerstag", "Freitag", "Samstag"},
+	"italian":    {"domenica", "lunedì", "martedì", "mercoledì", "giovedì", "venerdì", "sabato"},
+	"portuguese": {"domingo", "segunda-feira", "terça-feira", "quarta-feira", "quinta-feira", "sexta-feira", "sábado"},
+	"spanish":    {"domingo", "lunes", "martes", "miércoles", "jueves", "viernes", "sábado"},
+}
+
+// LocalLocale is used to format dates, like LocalTimezone
+var LocalLocale = DefaultLocale
+
+// Merge returns the locale with the fields that are set in override replaced
+func (l Locale) Merge(override Locale) Locale {
+	if override.Language != "" {
+		l.Language = override.Language
+	}
+
+	if override.Units != "" {
+		l.Units = override.Units
+	}
+
+	if override.Clock != "" {
+		l.Clock = override.Clock
+	}
+
+	if override.FirstWeekday != "" {
+		l.FirstWeekday = override.FirstWeekday
+	}
+
+	return l
+}
+
+// WithDefaults fills the fields that are not set from DefaultLocale
+func (l Locale) WithDefaults() Locale {
+	return DefaultLocale.Merge(l)
+}
+
+func (l Locale) Validate() error {
+	switch l.Units {
+	case "", "metric", "imperial":
+	default:
+		return fmt.Errorf("invalid units %q: use metric or imperial", l.Units)
+	}
+
+	switch l.Clock {
+	case "", "24h", "12h":
+	default:
+		return fmt.Errorf("invalid clock %q: use 24h or 12h", l.Clock)
+	}
+
+	if l.FirstWeekday != "" {
+		if _, err := l.Weekday(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Weekday returns the first day of the week
+func (l Locale) Weekday() (time.Weekday, error) {
+	name := l.WithDefaults().FirstWeekday
+
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if strings.EqualFold(d.String(), name) {
+			return d, nil
+		}
+	}
+
+	return time.Monday, fmt.Errorf("invalid first weekday %q", name)
+}
+
+// TimeLayout returns the layout of a time of day in the clock notation
+func (l Locale) TimeLayout() string {
+	if l.WithDefaults().Clock == "12h" {
+		return "3:04 PM"
+	}
+
+	return "15:04"
+}
+
+// WeekdayName returns the name of the weekday in the language
+func (l Locale) WeekdayName(d time.Weekday) string {
+	if names, ok := weekdayNames[strings.ToLower(l.WithDefaults().Language)]; ok {
+		return names[d]
+	}
+
+	return d.String()
+}
+
+// Week returns the number of the week of the year of t, with weeks that start
+// on the first weekday; the first week is the one with January 1st
+func (l Locale) Week(t time.Time) int {
+	first, _ := l.Weekday()
+	jan1 := time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, t.Location())
+	offset := (int(jan1.Weekday()) - int(first) + 7) % 7
+
+	return (t.YearDay()-1+offset)/7 + 1
+}
+
+// PromptInstructions tells the model which language and notations to use
+func (l Locale) PromptInstructions() []string {
+	l = l.WithDefaults()
+
+	units := "metric system"
+	if l.Units == "imperial" {
+		units = "imperial system"
+	}
+
+	clock := "24 hour clock notation"
+	if l.Clock == "12h" {
+		clock = "12 hour clock notation"
+	}
+
+	first, _ := l.Weekday()
+
+	return []string{
+		fmt.Sprintf("Use the %s and %s.", units, clock),
+		fmt.Sprintf("Write your response in %s and translate all entries to %s.", l.Language, l.Language),
+		fmt.Sprintf("Weeks start on %s.", first),
+	}
+}
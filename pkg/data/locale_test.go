@@ -0,0 +1,82 @@
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocale_Merge(t *testing.T) {
+	l := Locale{Language: "Dutch", Units: "metric"}.Merge(Locale{Language: "English", Clock: "12h"})
+
+	assert.Equal(t, Locale{Language: "English", Units: "metric", Clock: "12h"}, l)
+	assert.Equal(t, Locale{Language: "English", Units: "metric", Clock: "12h", FirstWeekday: "monday"}, l.WithDefaults())
+}
+
+func TestLocale_Validate(t *testing.T) {
+	assert.NoError(t, Locale{}.Validate())
+	assert.NoError(t, Locale{Units: "imperial", Clock: "12h", FirstWeekday: "Sunday"}.Validate())
+	assert.ErrorContains(t, Locale{Units: "nautical"}.Validate(), "invalid units")
+	assert.ErrorContains(t, Locale{Clock: "25h"}.Validate(), "invalid clock")
+	assert.ErrorContains(t, Locale{FirstWeekday: "someday"}.Validate(), "invalid first weekday")
+}
+
+func TestLocale_Weekday(t *testing.T) {
+	d, err := Locale{}.Weekday()
+	require.NoError(t, err)
+	assert.Equal(t, time.Monday, d)
+
+	d, err = Locale{FirstWeekday: "sunday"}.Weekday()
+	require.NoError(t, err)
+	assert.Equal(t, time.Sunday, d)
+}
+
+func TestLocale_PromptInstructions(t *testing.T) {
+	assert.Equal(t, []string{
+		"Use the metric system and 24 hour clock notation.",
+		"Write your response in English and translate all entries to English.",
+		"Weeks start on Monday.",
+	}, Locale{}.PromptInstructions())
+
+	assert.Equal(t, []string{
+		"Use the imperial system and 12 hour clock notation.",
+		"Write your response in Dutch and translate all entries to Dutch.",
+		"Weeks start on Sunday.",
+	}, Locale{Language: "Dutch", Units: "imperial", Clock: "12h", FirstWeekday: "sunday"}.PromptInstructions())
+}
+
+func TestHumanTime_FormatDate_Locale(t *testing.T) {
+	LocalTimezone = time.UTC
+	LocalLocale = Locale{Clock: "12h"}
+
+	defer func() {
+		LocalTimezone = time.Local
+		LocalLocale = DefaultLocale
+	}()
+
+	ht := HumanTime{time.Date(2025, 3, 3, 14, 30, 0, 0, time.UTC)}
+
+	assert.Equal(t, "2025-03-03 2:30 PM", ht.FormatDate())
+
+	j, err := ht.MarshalJSON()
+	require.NoError(t, err)
+	assert.JSONEq(t, `"2025-03-03 14:30"`, string(j), "JSON should not be localized")
+}
+
+func TestLocale_Week(t *testing.T) {
+	// 2026 starts on a Thursday
+	sunday := time.Date(2026, 1, 4, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 1, Locale{FirstWeekday: "monday"}.Week(sunday))
+	assert.Equal(t, 2, Locale{FirstWeekday: "sunday"}.Week(sunday), "The week should start on the first weekday")
+	assert.Equal(t, 1, Locale{}.Week(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, 53, Locale{}.Week(time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestLocale_WeekdayName(t *testing.T) {
+	assert.Equal(t, "Sunday", Locale{}.WeekdayName(time.Sunday))
+	assert.Equal(t, "woensdag", Locale{Language: "Dutch"}.WeekdayName(time.Wednesday))
+	assert.Equal(t, "Friday", Locale{Language: "Klingon"}.WeekdayName(time.Friday), "Unknown languages should fall back to English")
+}
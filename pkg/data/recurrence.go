@@ -0,0 +1,159 @@
+package data
+
+import (
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aquasecurity/table"
+)
+
+// SetRepeat makes the entry recur according to an RRULE; an empty rule stops
+// the recurrence
+func (e *Entry) SetRepeat(rule string) error {
+	if rule == "" {
+		e.Repeat = ""
+		return nil
+	}
+
+	r, err := ParseRRule(rule)
+	if err != nil {
+		return err
+	}
+
+	e.Repeat = r.String()
+
+	return nil
+}
+
+// AddException skips the occurrence on the date, formatted as YYYY-MM-DD
+func (e *Entry) AddException(d string) error {
+	t, err := time.ParseInLocation("2006-01-02", d, LocalTimezone)
+	if err != nil {
+		return err
+	}
+
+	d = t.Format("2006-01-02")
+
+	if !slices.Contains(e.Exceptions, d) {
+		e.Exceptions = append(e.Exceptions, d)
+		slices.Sort(e.Exceptions)
+	}
+
+	return nil
+}
+
+// StopRepeat ends the series before the day of t, by setting the UNTIL of
+// its rule
+func (e *Entry) StopRepeat(t time.Time) error {
+	r, err := ParseRRule(e.Repeat)
+	if err != nil {
+		return err
+	}
+
+	r.Count = 0
+	r.Until = startOfDay(t).Add(-time.Second)
+	e.Repeat = r.String()
+
+	return nil
+}
+
+// Occurrences returns a copy of the entry for every occurrence of its series
+// that overlaps with the period from from to to, inclusive
+func (e *Entry) Occurrences(from, to time.Time) (Entries, error) {
+	r, err := ParseRRule(e.Repeat)
+	if err != nil {
+		return nil, err
+	}
+
+	duration := e.Duration()
+
+	var result Entries
+
+	for _, t := range r.Between(e.Date.Time, from.Add(-duration), to) {
+		if slices.Contains(e.Exceptions, t.In(LocalTimezone).Format("2006-01-02")) {
+			continue
+		}
+
+		if duration > 0 && !t.Add(duration).After(from) && t.Before(from) {
+			continue
+		}
+
+		o := *e
+		o.Date = HumanTime{t}
+
+		if e.End != nil {
+			o.End = &HumanTime{t.Add(duration)}
+		}
+
+		o.DateString = o.FormattedDate()
+		result = append(result, o)
+	}
+
+	return result, nil
+}
+
+// NextOccurrence returns the first occurrence from t on, or false if the
+// series has ended
+func (e *Entry) NextOccurrence(t time.Time) (time.Time, bool) {
+	occurrences, err := e.Occurrences(t, t.AddDate(10, 0, 0))
+	if err != nil || len(occurrences) == 0 {
+		return time.Time{}, false
+	}
+
+	return occurrences[0].Date.Time, true
+}
+
+// Expand replaces the recurring entries by their occurrences between from and
+// to, sorted by date
+func (es Entries) Expand(from, to time.Time) Entries {
+	result := make(Entries, 0, len(es))
+
+	for _, e := range es {
+		if e.Repeat == "" {
+			result = append(result, e)
+			continue
+		}
+
+		occurrences, err := e.Occurrences(from, to)
+		if err != nil {
+			result = append(result, e)
+			continue
+		}
+
+		result = append(result, occurrences...)
+	}
+
+	slices.SortStableFunc(result, func(a, b Entry) int {
+		return a.Date.Compare(b.Date.Time)
+	})
+
+	return result
+}
+
+// PrintSeriesTo prints the recurring entries with their rule and next
+// occurrence from now on
+func (es Entries) PrintSeriesTo(w io.Writer, now time.Time) {
+	t := table.New(w)
+	t.AddHeaders("ID", "Start", "Title", "Repeat", "Exceptions", "Next")
+
+	for _, e := range es {
+		next := "ended"
+		if n, ok := e.NextOccurrence(now); ok {
+			next = (&Entry{Date: HumanTime{n}, AllDay: e.AllDay}).FormattedDate()
+		}
+
+		t.AddRow(
+			strconv.FormatUint(e.ID, 10),
+			e.DateString,
+			e.Summary,
+			e.Repeat,
+			strings.Join(e.Exceptions, ", "),
+			next,
+		)
+	}
+
+	t.Render()
+}
@@ -0,0 +1,85 @@
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEntry_SetRepeat(t *testing.T) {
+	e := Entry{}
+
+	require.NoError(t, e.SetRepeat("RRULE:FREQ=weekly;BYDAY=tu"))
+	assert.Equal(t, "FREQ=WEEKLY;BYDAY=TU", e.Repeat)
+
+	require.ErrorIs(t, e.SetRepeat("FREQ=SECONDLY"), ErrInvalidRRule)
+
+	require.NoError(t, e.SetRepeat(""))
+	assert.Empty(t, e.Repeat)
+}
+
+func TestEntry_AddException(t *testing.T) {
+	e := Entry{}
+
+	require.NoError(t, e.AddException("2025-03-04"))
+	require.NoError(t, e.AddException("2025-01-02"))
+	require.NoError(t, e.AddException("2025-03-04"))
+	require.Error(t, e.AddException("tomorrow"))
+
+	assert.Equal(t, []string{"2025-01-02", "2025-03-04"}, e.Exceptions)
+}
+
+func TestEntry_Occurrences(t *testing.T) {
+	LocalTimezone = time.UTC
+	defer func() { LocalTimezone = time.Local }()
+
+	start := time.Date(2025, time.January, 6, 22, 0, 0, 0, time.UTC) // Monday
+	e := Entry{
+		Summary:    "Night shift",
+		Date:       HumanTime{start},
+		End:        &HumanTime{start.Add(10 * time.Hour)},
+		Repeat:     "FREQ=WEEKLY",
+		Exceptions: []string{"2025-01-20"},
+	}
+
+	// The occurrence of Monday the 13th lasts until Tuesday morning
+	occurrences, err := e.Occurrences(
+		time.Date(2025, time.January, 14, 0, 0, 0, 0, time.UTC),
+		time.Date(2025, time.January, 28, 0, 0, 0, 0, time.UTC),
+	)
+	require.NoError(t, err)
+	require.Len(t, occurrences, 2)
+
+	assert.Equal(t, "2025-01-13 22:00", occurrences[0].Date.Format("2006-01-02 15:04"))
+	assert.Equal(t, "2025-01-14 08:00", occurrences[0].End.Format("2006-01-02 15:04"))
+	assert.Equal(t, "2025-01-27 22:00", occurrences[1].Date.Format("2006-01-02 15:04"))
+	assert.Equal(t, start, e.Date.Time, "The series itself should not change")
+
+	require.NoError(t, e.StopRepeat(time.Date(2025, time.January, 27, 9, 0, 0, 0, time.UTC)))
+	assert.Equal(t, "FREQ=WEEKLY;UNTIL=20250126T235959Z", e.Repeat)
+
+	next, ok := e.NextOccurrence(time.Date(2025, time.January, 21, 0, 0, 0, 0, time.UTC))
+	assert.False(t, ok, "The series should have ended")
+	assert.True(t, next.IsZero())
+}
+
+func TestEntries_Expand(t *testing.T) {
+	LocalTimezone = time.UTC
+	defer func() { LocalTimezone = time.Local }()
+
+	day := func(d int) time.Time { return time.Date(2025, time.May, d, 0, 0, 0, 0, time.UTC) }
+
+	es := Entries{
+		{Summary: "Once", Date: HumanTime{day(3).Add(9 * time.Hour)}},
+		{Summary: "Daily", Date: HumanTime{day(1)}, AllDay: true, Repeat: "FREQ=DAILY;COUNT=3"},
+	}
+
+	var summaries []string
+	for _, e := range es.Expand(day(2), day(5)) {
+		summaries = append(summaries, e.Summary+" "+e.Date.Format("02"))
+	}
+
+	assert.Equal(t, []string{"Daily 02", "Daily 03", "Once 03"}, summaries)
+}
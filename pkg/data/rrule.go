@@ -0,0 +1,477 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxRRulePeriods stops the expansion of rules that never match, like the
+// 30th of February. It counts from the first period that is expanded, so old
+// series without a COUNT still have occurrences.
+const maxRRulePeriods = 10000
+
+var ErrInvalidRRule = errors.New("invalid RRULE")
+
+var rruleWeekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// RRule is a recurrence rule in the RFC 5545 syntax, eg.
+// FREQ=MONTHLY;BYDAY=-1FR;COUNT=12. It supports FREQ, INTERVAL, COUNT, UNTIL,
+// BYDAY, BYMONTHDAY and BYMONTH; weeks start on Monday. Like in RFC 5545,
+// BYMONTHDAY limits the days of BYDAY instead of adding to them.
+type RRule struct {
+	Freq       string
+	Interval   int
+	Count      int
+	Until      time.Time
+	ByDay      []RRuleDay
+	ByMonthDay []int
+	ByMonth    []time.Month
+}
+
+// RRuleDay is a weekday, optionally the nth (or nth last if negative) in the
+// month or year, eg. 2MO or -1FR
+type RRuleDay struct {
+	N       int
+	Weekday time.Weekday
+}
+
+func (d RRuleDay) String() string {
+	s := ""
+	if d.N != 0 {
+		s = strconv.Itoa(d.N)
+	}
+
+	for k, v := range rruleWeekdays {
+		if v == d.Weekday {
+			return s + k
+		}
+	}
+
+	return s
+}
+
+// ParseRRule parses a recurrence rule, with or without the RRULE: prefix
+func ParseRRule(s string) (*RRule, error) {
+	r := &RRule{Interval: 1}
+
+	s = strings.TrimPrefix(strings.TrimSpace(s), "RRULE:")
+
+	for _, part := range strings.Split(s, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidRRule, part)
+		}
+
+		if err := r.set(strings.ToUpper(key), strings.ToUpper(value)); err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrInvalidRRule, key, err)
+		}
+	}
+
+	switch r.Freq {
+	case "DAILY", "WEEKLY", "MONTHLY", "YEARLY":
+	case "":
+		return nil, fmt.Errorf("%w: FREQ is missing", ErrInvalidRRule)
+	default:
+		return nil, fmt.Errorf("%w: unsupported FREQ %s", ErrInvalidRRule, r.Freq)
+	}
+
+	if r.Count > 0 && !r.Until.IsZero() {
+		return nil, fmt.Errorf("%w: COUNT and UNTIL can not be combined", ErrInvalidRRule)
+	}
+
+	if r.Freq == "DAILY" || r.Freq == "WEEKLY" {
+		if slices.ContainsFunc(r.ByDay, func(bd RRuleDay) bool { return bd.N != 0 }) {
+			return nil, fmt.Errorf("%w: BYDAY with a number needs FREQ=MONTHLY or YEARLY", ErrInvalidRRule)
+		}
+	}
+
+	if r.Freq == "WEEKLY" && len(r.ByMonthDay) > 0 {
+		return nil, fmt.Errorf("%w: BYMONTHDAY can not be combined with FREQ=WEEKLY", ErrInvalidRRule)
+	}
+
+	return r, nil
+}
+
+func (r *RRule) set(key, value string) error {
+	var err error
+
+	switch key {
+	case "FREQ":
+		r.Freq = value
+	case "INTERVAL":
+		r.Interval, err = positive(value)
+	case "COUNT":
+		r.Count, err = positive(value)
+	case "UNTIL":
+		r.Until, err = parseRRuleTime(value)
+	case "BYDAY":
+		r.ByDay, err = parseList(value, parseRRuleDay)
+	case "BYMONTHDAY":
+		r.ByMonthDay, err = parseList(value, func(s string) (int, error) {
+			d, err := strconv.Atoi(s)
+			if err != nil || d == 0 || d < -31 || d > 31 {
+				return 0, fmt.Errorf("invalid day %q", s)
+			}
+
+			return d, nil
+		})
+	case "BYMONTH":
+		r.ByMonth, err = parseList(value, func(s string) (time.Month, error) {
+			m, err := strconv.Atoi(s)
+			if err != nil || m < 1 || m > 12 {
+				return 0, fmt.Errorf("invalid month %q", s)
+			}
+
+			return time.Month(m), nil
+		})
+	case "WKST":
+		if value != "MO" {
+			err = errors.New("only weeks starting on MO are supported")
+		}
+	default:
+		err = errors.New("not supported")
+	}
+
+	return err
+}
+
+func positive(s string) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("not a positive number: %q", s)
+	}
+
+	return n, nil
+}
+
+func parseList[T any](s string, parse func(string) (T, error)) ([]T, error) {
+	var result []T
+
+	for _, v := range strings.Split(s, ",") {
+		p, err := parse(v)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, p)
+	}
+
+	return result, nil
+}
+
+func parseRRuleDay(s string) (RRuleDay, error) {
+	if len(s) < 2 {
+		return RRuleDay{}, fmt.Errorf("invalid day %q", s)
+	}
+
+	wd, ok := rruleWeekdays[s[len(s)-2:]]
+	if !ok {
+		return RRuleDay{}, fmt.Errorf("invalid day %q", s)
+	}
+
+	d := RRuleDay{Weekday: wd}
+
+	if n := s[:len(s)-2]; n != "" {
+		var err error
+
+		if d.N, err = strconv.Atoi(n); err != nil || d.N == 0 || d.N < -53 || d.N > 53 {
+			return RRuleDay{}, fmt.Errorf("invalid day %q", s)
+		}
+	}
+
+	return d, nil
+}
+
+func parseRRuleTime(s string) (time.Time, error) {
+	if t, err := time.Parse("20060102T150405Z", s); err == nil {
+		return t, nil
+	}
+
+	if t, err := time.ParseInLocation("20060102T150405", s, LocalTimezone); err == nil {
+		return t, nil
+	}
+
+	t, err := time.ParseInLocation("20060102", s, LocalTimezone)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	// A date includes the whole day
+	return t.AddDate(0, 0, 1).Add(-time.Second), nil
+}
+
+func (r *RRule) String() string {
+	parts := []string{"FREQ=" + r.Freq}
+
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+
+	if len(r.ByDay) > 0 {
+		parts = append(parts, "BYDAY="+joinList(r.ByDay, RRuleDay.String))
+	}
+
+	if len(r.ByMonthDay) > 0 {
+		parts = append(parts, "BYMONTHDAY="+joinList(r.ByMonthDay, strconv.Itoa))
+	}
+
+	if len(r.ByMonth) > 0 {
+		parts = append(parts, "BYMONTH="+joinList(r.ByMonth, func(m time.Month) string { return strconv.Itoa(int(m)) }))
+	}
+
+	return strings.Join(parts, ";")
+}
+
+func joinList[T any](l []T, format func(T) string) string {
+	s := make([]string, 0, len(l))
+	for _, v := range l {
+		s = append(s, format(v))
+	}
+
+	return strings.Join(s, ",")
+}
+
+// Between returns the occurrences of a series that starts at start, from
+// from up to and including to
+func (r *RRule) Between(start, from, to time.Time) []time.Time {
+	start = start.In(LocalTimezone)
+
+	var (
+		result []time.Time
+		count  int
+	)
+
+	first := r.firstPeriod(start, from)
+
+	for period := first; period < first+maxRRulePeriods; period++ {
+		candidates := r.candidates(start, period)
+
+		for _, t := range candidates {
+			if t.Before(start) {
+				continue
+			}
+
+			if t.After(to) || (!r.Until.IsZero() && t.After(r.Until)) {
+				return result
+			}
+
+			count++
+			if r.Count > 0 && count > r.Count {
+				return result
+			}
+
+			if !t.Before(from) {
+				result = append(result, t)
+			}
+		}
+	}
+
+	return result
+}
+
+// firstPeriod returns the last period after start that begins at or before
+// from. The periods before it have no occurrences from from on, so they are
+// skipped, unless the occurrences have to be counted for COUNT.
+func (r *RRule) firstPeriod(start, from time.Time) int {
+	if r.Count > 0 || !from.After(start) {
+		return 0
+	}
+
+	sy, sm, sd := start.Date()
+	fy, fm, fd := from.In(LocalTimezone).Date()
+
+	var n int
+
+	switch r.Freq {
+	case "DAILY":
+		n = daysBetween(sy, sm, sd, fy, fm, fd)
+	case "WEEKLY":
+		monday := sd - (int(start.Weekday())+6)%7
+		n = daysBetween(sy, sm, monday, fy, fm, fd) / 7
+	case "MONTHLY":
+		n = (fy-sy)*12 + int(fm-sm)
+	case "YEARLY":
+		n = fy - sy
+	}
+
+	return n / r.Interval
+}
+
+// daysBetween returns the number of days from one date to another, regardless
+// of daylight saving time
+func daysBetween(y1 int, m1 time.Month, d1, y2 int, m2 time.Month, d2 int) int {
+	a := time.Date(y1, m1, d1, 0, 0, 0, 0, time.UTC)
+	b := time.Date(y2, m2, d2, 0, 0, 0, 0, time.UTC)
+
+	return int(b.Sub(a).Hours() / 24)
+}
+
+// candidates returns the sorted occurrences in the nth period after start
+func (r *RRule) candidates(start time.Time, n int) []time.Time {
+	y, m, d := start.Date()
+	n *= r.Interval
+
+	var days []time.Time
+
+	switch r.Freq {
+	case "DAILY":
+		days = []time.Time{r.day(y, m, d+n, start)}
+	case "WEEKLY":
+		monday := d - (int(start.Weekday())+6)%7 + 7*n
+		days = r.weekly(y, m, monday, start)
+	case "MONTHLY":
+		days = r.monthly(y, m+time.Month(n), start)
+	case "YEARLY":
+		days = r.yearly(y+n, m, start)
+	}
+
+	days = slices.DeleteFunc(days, func(t time.Time) bool {
+		return !r.matches(t)
+	})
+
+	slices.SortFunc(days, func(a, b time.Time) int { return a.Compare(b) })
+
+	return slices.CompactFunc(days, time.Time.Equal)
+}
+
+// day returns the date at the time of start; it normalizes overflowing days
+func (r *RRule) day(y int, m time.Month, d int, start time.Time) time.Time {
+	return time.Date(y, m, d, start.Hour(), start.Minute(), start.Second(), 0, LocalTimezone)
+}
+
+func (r *RRule) weekly(y int, m time.Month, monday int, start time.Time) []time.Time {
+	if len(r.ByDay) == 0 {
+		return []time.Time{r.day(y, m, monday+(int(start.Weekday())+6)%7, start)}
+	}
+
+	days := make([]time.Time, 0, len(r.ByDay))
+	for _, bd := range r.ByDay {
+		days = append(days, r.day(y, m, monday+(int(bd.Weekday)+6)%7, start))
+	}
+
+	return days
+}
+
+// monthly returns the days of the month that match BYDAY or BYMONTHDAY, or
+// the day of the month of start
+func (r *RRule) monthly(y int, m time.Month, start time.Time) []time.Time {
+	first := r.day(y, m, 1, start)
+	y, m = first.Year(), first.Month()
+	length := first.AddDate(0, 1, -1).Day()
+
+	var days []time.Time
+
+	switch {
+	case len(r.ByDay) > 0:
+		for _, bd := range r.ByDay {
+			days = append(days, weekdaysIn(first, length, bd)...)
+		}
+	case len(r.ByMonthDay) > 0:
+		for _, md := range r.ByMonthDay {
+			if md < 0 {
+				md = length + md + 1
+			}
+
+			if md >= 1 && md <= length {
+				days = append(days, r.day(y, m, md, start))
+			}
+		}
+	case start.Day() <= length:
+		days = append(days, r.day(y, m, start.Day(), start))
+	}
+
+	return days
+}
+
+// yearly returns the days of the year that match BYMONTH, BYDAY or
+// BYMONTHDAY, or the day of start in the month of start. Without BYMONTH, the
+// number of a BYDAY counts in the year, eg. 20MO is the 20th Monday.
+func (r *RRule) yearly(y int, m time.Month, start time.Time) []time.Time {
+	var days []time.Time
+
+	switch {
+	case len(r.ByMonth) > 0:
+		for _, month := range r.ByMonth {
+			days = append(days, r.monthly(y, month, start)...)
+		}
+	case len(r.ByDay) > 0:
+		first := r.day(y, time.January, 1, start)
+		length := first.AddDate(1, 0, -1).YearDay()
+
+		for _, bd := range r.ByDay {
+			days = append(days, weekdaysIn(first, length, bd)...)
+		}
+	case len(r.ByMonthDay) > 0:
+		for month := time.January; month <= time.December; month++ {
+			days = append(days, r.monthly(y, month, start)...)
+		}
+	default:
+		days = r.monthly(y, m, start)
+	}
+
+	return days
+}
+
+// weekdaysIn returns the days with the weekday in the length days from first
+// on, or only the nth (last) of them
+func weekdaysIn(first time.Time, length int, bd RRuleDay) []time.Time {
+	var days []time.Time
+
+	for d := 1 + (int(bd.Weekday)-int(first.Weekday())+7)%7; d <= length; d += 7 {
+		days = append(days, first.AddDate(0, 0, d-1))
+	}
+
+	switch {
+	case bd.N > 0 && bd.N <= len(days):
+		return days[bd.N-1 : bd.N]
+	case bd.N < 0 && -bd.N <= len(days):
+		return days[len(days)+bd.N : len(days)+bd.N+1]
+	case bd.N != 0:
+		return nil
+	}
+
+	return days
+}
+
+// matches filters the days of daily rules, the days of weekly and monthly
+// rules by month, and the days of BYDAY by BYMONTHDAY
+func (r *RRule) matches(t time.Time) bool {
+	if len(r.ByMonth) > 0 && !slices.Contains(r.ByMonth, t.Month()) {
+		return false
+	}
+
+	if r.Freq == "DAILY" && len(r.ByDay) > 0 &&
+		!slices.ContainsFunc(r.ByDay, func(bd RRuleDay) bool { return bd.Weekday == t.Weekday() }) {
+		return false
+	}
+
+	if len(r.ByMonthDay) > 0 && (r.Freq == "DAILY" || len(r.ByDay) > 0) {
+		length := time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, t.Location()).Day()
+
+		return slices.ContainsFunc(r.ByMonthDay, func(md int) bool {
+			return md == t.Day() || md == t.Day()-length-1
+		})
+	}
+
+	return true
+}
@@ -0,0 +1,187 @@
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRRule_Errors(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"INTERVAL=2",
+		"FREQ=HOURLY",
+		"FREQ=WEEKLY;BYDAY=XX",
+		"FREQ=MONTHLY;BYMONTHDAY=32",
+		"FREQ=DAILY;COUNT=0",
+		"FREQ=DAILY;COUNT=3;UNTIL=20250101",
+		"FREQ=WEEKLY;WKST=SU",
+		"FREQ=DAILY;BYSETPOS=1",
+		"FREQ=WEEKLY;BYDAY=1MO",
+		"FREQ=DAILY;BYDAY=-1FR",
+		"FREQ=WEEKLY;BYMONTHDAY=1",
+	} {
+		_, err := ParseRRule(s)
+		require.ErrorIs(t, err, ErrInvalidRRule, s)
+	}
+}
+
+func TestRRule_String(t *testing.T) {
+	r, err := ParseRRule("RRULE:freq=monthly;interval=2;byday=-1FR,2mo;count=4")
+	require.NoError(t, err)
+
+	assert.Equal(t, "FREQ=MONTHLY;INTERVAL=2;COUNT=4;BYDAY=-1FR,2MO", r.String())
+
+	again, err := ParseRRule(r.String())
+	require.NoError(t, err)
+	assert.Equal(t, r, again)
+}
+
+func dates(ts []time.Time) []string {
+	result := make([]string, 0, len(ts))
+	for _, t := range ts {
+		result = append(result, t.Format("2006-01-02 15:04"))
+	}
+
+	return result
+}
+
+func TestRRule_Between(t *testing.T) {
+	LocalTimezone = time.UTC
+	defer func() { LocalTimezone = time.Local }()
+
+	start := time.Date(2025, time.January, 7, 19, 30, 0, 0, time.UTC) // Tuesday
+	from := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		rule     string
+		from, to time.Time
+		expected []string
+	}{
+		{
+			rule:     "FREQ=WEEKLY;BYDAY=TU,TH;COUNT=4",
+			from:     from,
+			to:       to,
+			expected: []string{"2025-01-07 19:30", "2025-01-09 19:30", "2025-01-14 19:30", "2025-01-16 19:30"},
+		},
+		{
+			rule:     "FREQ=WEEKLY;INTERVAL=2",
+			from:     time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC),
+			to:       to,
+			expected: []string{"2025-02-04 19:30", "2025-02-18 19:30"},
+		},
+		{
+			rule:     "FREQ=MONTHLY;BYDAY=-1FR",
+			from:     from,
+			to:       to,
+			expected: []string{"2025-01-31 19:30", "2025-02-28 19:30"},
+		},
+		{
+			rule:     "FREQ=MONTHLY;BYMONTHDAY=-1",
+			from:     from,
+			to:       to,
+			expected: []string{"2025-01-31 19:30", "2025-02-28 19:30"},
+		},
+		{
+			rule:     "FREQ=DAILY;UNTIL=20250110",
+			from:     from,
+			to:       to,
+			expected: []string{"2025-01-07 19:30", "2025-01-08 19:30", "2025-01-09 19:30", "2025-01-10 19:30"},
+		},
+		{
+			rule:     "FREQ=DAILY;BYDAY=SA,SU",
+			from:     from,
+			to:       time.Date(2025, time.January, 15, 0, 0, 0, 0, time.UTC),
+			expected: []string{"2025-01-11 19:30", "2025-01-12 19:30"},
+		},
+		{
+			rule:     "FREQ=MONTHLY;BYDAY=FR;BYMONTHDAY=13",
+			from:     from,
+			to:       time.Date(2026, time.April, 1, 0, 0, 0, 0, time.UTC),
+			expected: []string{"2025-06-13 19:30", "2026-02-13 19:30", "2026-03-13 19:30"},
+		},
+		{
+			rule:     "FREQ=DAILY;BYDAY=MO;BYMONTHDAY=1,-1",
+			from:     from,
+			to:       time.Date(2025, time.October, 1, 0, 0, 0, 0, time.UTC),
+			expected: []string{"2025-03-31 19:30", "2025-06-30 19:30", "2025-09-01 19:30"},
+		},
+		{
+			rule:     "FREQ=YEARLY;BYDAY=20MO,-1SU",
+			from:     from,
+			to:       time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC),
+			expected: []string{"2025-05-19 19:30", "2025-12-28 19:30", "2026-05-18 19:30", "2026-12-27 19:30"},
+		},
+		{
+			rule:     "FREQ=YEARLY;BYDAY=SU;COUNT=3",
+			from:     from,
+			to:       to,
+			expected: []string{"2025-01-12 19:30", "2025-01-19 19:30", "2025-01-26 19:30"},
+		},
+		{
+			rule:     "FREQ=YEARLY;BYDAY=1MO;BYMONTH=2,3",
+			from:     from,
+			to:       time.Date(2025, time.April, 1, 0, 0, 0, 0, time.UTC),
+			expected: []string{"2025-02-03 19:30", "2025-03-03 19:30"},
+		},
+		{
+			rule:     "FREQ=YEARLY;BYMONTHDAY=1",
+			from:     from,
+			to:       time.Date(2025, time.April, 1, 0, 0, 0, 0, time.UTC),
+			expected: []string{"2025-02-01 19:30", "2025-03-01 19:30"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			r, err := ParseRRule(tt.rule)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expected, dates(r.Between(start, tt.from, tt.to)))
+		})
+	}
+}
+
+func TestRRule_Between_OldSeries(t *testing.T) {
+	LocalTimezone = time.UTC
+	defer func() { LocalTimezone = time.Local }()
+
+	start := time.Date(1990, time.January, 1, 19, 30, 0, 0, time.UTC) // Monday
+	from := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	for rule, expected := range map[string][]string{
+		"FREQ=DAILY;INTERVAL=3":    {"2025-03-01 19:30", "2025-03-04 19:30", "2025-03-07 19:30"},
+		"FREQ=WEEKLY;BYDAY=MO,TH":  {"2025-03-03 19:30", "2025-03-06 19:30"},
+		"FREQ=MONTHLY;INTERVAL=5":  {},
+		"FREQ=YEARLY;BYMONTHDAY=5": {"2025-03-05 19:30"},
+	} {
+		r, err := ParseRRule(rule)
+		require.NoError(t, err)
+
+		assert.ElementsMatch(t, expected, dates(r.Between(start, from, from.AddDate(0, 0, 9))), rule)
+	}
+
+	r, err := ParseRRule("FREQ=MONTHLY;INTERVAL=5")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2025-06-01 19:30"}, dates(r.Between(start, from, from.AddDate(0, 4, 0))), "The interval should be kept")
+}
+
+func TestRRule_Between_LeapDay(t *testing.T) {
+	LocalTimezone = time.UTC
+	defer func() { LocalTimezone = time.Local }()
+
+	r, err := ParseRRule("FREQ=YEARLY")
+	require.NoError(t, err)
+
+	start := time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2033, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, []string{"2024-02-29 00:00", "2028-02-29 00:00", "2032-02-29 00:00"}, dates(r.Between(start, start, to)))
+
+	r, err = ParseRRule("FREQ=MONTHLY;BYMONTHDAY=30;BYMONTH=2")
+	require.NoError(t, err)
+	assert.Empty(t, r.Between(start, start, to), "A rule without occurrences should stop")
+}
@@ -0,0 +1,61 @@
+package data
+
+import (
+	"slices"
+	"strings"
+)
+
+// Tag labels entries by topic, eg. school, work or kids, across sources
+type Tag struct {
+	Name string `gorm:"primaryKey"`
+}
+
+// NormalizeTags trims and lowercases the tag names, and drops empty names and
+// duplicates
+func NormalizeTags(names []string) []string {
+	result := make([]string, 0, len(names))
+
+	for _, n := range names {
+		n = strings.ToLower(strings.TrimSpace(n))
+		if n != "" && !slices.Contains(result, n) {
+			result = append(result, n)
+		}
+	}
+
+	return result
+}
+
+// AddTags tags the entry with the names
+func (e *Entry) AddTags(names ...string) {
+	for _, n := range NormalizeTags(names) {
+		if !e.HasTag(n) {
+			e.Tags = append(e.Tags, Tag{Name: n})
+		}
+	}
+}
+
+// RemoveTags removes the tags with the names from the entry
+func (e *Entry) RemoveTags(names ...string) {
+	names = NormalizeTags(names)
+
+	e.Tags = slices.DeleteFunc(e.Tags, func(t Tag) bool {
+		return slices.Contains(names, t.Name)
+	})
+}
+
+// HasTag returns whether the entry is tagged with the name
+func (e *Entry) HasTag(name string) bool {
+	return slices.ContainsFunc(e.Tags, func(t Tag) bool { return t.Name == name })
+}
+
+// TagNames returns the sorted names of the tags of the entry
+func (e *Entry) TagNames() []string {
+	names := make([]string, 0, len(e.Tags))
+	for _, t := range e.Tags {
+		names = append(names, t.Name)
+	}
+
+	slices.Sort(names)
+
+	return names
+}
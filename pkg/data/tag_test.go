@@ -0,0 +1,32 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	assert.Equal(t, []string{"kids", "school"}, NormalizeTags([]string{" Kids", "school", "", "KIDS"}))
+	assert.Empty(t, NormalizeTags(nil))
+}
+
+func TestEntry_Tags(t *testing.T) {
+	e := &Entry{}
+
+	e.AddTags("school", "Kids", "kids")
+	assert.Equal(t, []string{"kids", "school"}, e.TagNames())
+	assert.True(t, e.HasTag("kids"))
+
+	e.RemoveTags("SCHOOL", "work")
+	assert.Equal(t, []string{"kids"}, e.TagNames())
+	assert.False(t, e.HasTag("school"))
+}
+
+func TestEntry_MarshalJSON_Tags(t *testing.T) {
+	e := Entry{Summary: "Swimming", AllDay: true, Tags: []Tag{{Name: "outdoor"}, {Name: "kids"}}}
+
+	b, err := e.MarshalJSON()
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), `"Tags":["kids","outdoor"]`)
+}
@@ -0,0 +1,110 @@
+package data
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/aquasecurity/table"
+)
+
+type TaskStatus string
+
+var ErrInvalidStatus = errors.New("invalid status")
+
+const (
+	OPEN      TaskStatus = "open"
+	DONE      TaskStatus = "done"
+	CANCELLED TaskStatus = "cancelled"
+)
+
+// IsTask returns whether the entry is a task rather than an event
+func (e *Entry) IsTask() bool {
+	return e.Status != ""
+}
+
+// SetStatus sets the status of the task; a task that is done is completed now
+func (e *Entry) SetStatus(s string, now time.Time) error {
+	switch TaskStatus(s) {
+	case OPEN:
+		e.Status = OPEN
+		e.CompletedAt = nil
+	case DONE:
+		e.Complete(now)
+	case CANCELLED:
+		e.Status = CANCELLED
+		e.CompletedAt = nil
+	default:
+		return ErrInvalidStatus
+	}
+
+	return nil
+}
+
+// Complete marks the task as done now
+func (e *Entry) Complete(now time.Time) {
+	e.Status = DONE
+	e.CompletedAt = &now
+}
+
+// SetDue sets the due date of the task, relative to now, see ParseDateTime;
+// an empty date removes it
+func (e *Entry) SetDue(d string, now time.Time) error {
+	if d == "" {
+		e.Due = nil
+		return nil
+	}
+
+	t, err := parseDate(d, now)
+	if err != nil {
+		return err
+	}
+
+	e.Due = &HumanTime{t}
+
+	return nil
+}
+
+// IsOverdue returns whether the task is still open after its due date
+func (e *Entry) IsOverdue(now time.Time) bool {
+	return e.Status == OPEN && e.Due != nil && e.Due.Before(startOfDay(now))
+}
+
+// FormattedDue formats the due date of the task, or is empty without one
+func (e *Entry) FormattedDue() string {
+	if e.Due == nil {
+		return ""
+	}
+
+	return e.Due.formatAs(true, "")
+}
+
+// PrintTasksTo prints the tasks with their status and due date
+func (es Entries) PrintTasksTo(w io.Writer, now time.Time) {
+	t := table.New(w)
+	t.AddHeaders("ID", "Status", "Title", "Due", "Importance", "Source")
+
+	for _, e := range es {
+		status := string(e.Status)
+		if e.IsOverdue(now) {
+			status = "overdue"
+		}
+
+		source := ""
+		if e.Source != nil {
+			source = e.Source.Name
+		}
+
+		t.AddRow(
+			strconv.FormatUint(e.ID, 10),
+			status,
+			e.Summary,
+			e.FormattedDue(),
+			string(e.Importance),
+			source,
+		)
+	}
+
+	t.Render()
+}
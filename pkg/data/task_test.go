@@ -0,0 +1,65 @@
+package data
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEntry_SetStatus(t *testing.T) {
+	now := time.Date(2025, time.July, 10, 12, 0, 0, 0, LocalTimezone)
+
+	e := &Entry{}
+	assert.False(t, e.IsTask())
+
+	require.NoError(t, e.SetStatus("done", now))
+	assert.True(t, e.IsTask())
+	assert.Equal(t, DONE, e.Status)
+	require.NotNil(t, e.CompletedAt)
+	assert.Equal(t, now, *e.CompletedAt)
+
+	require.NoError(t, e.SetStatus("open", now))
+	assert.Nil(t, e.CompletedAt, "A reopened task is not completed")
+
+	require.ErrorIs(t, e.SetStatus("later", now), ErrInvalidStatus)
+	assert.Equal(t, OPEN, e.Status)
+}
+
+func TestEntry_IsOverdue(t *testing.T) {
+	now := time.Date(2025, time.July, 10, 12, 0, 0, 0, LocalTimezone)
+
+	e := &Entry{Status: OPEN}
+	assert.False(t, e.IsOverdue(now), "A task without a due date is never overdue")
+
+	require.NoError(t, e.SetDue("2025-07-10", now))
+	assert.False(t, e.IsOverdue(now), "A task is not overdue on its due date")
+
+	require.NoError(t, e.SetDue("2025-07-09", now))
+	assert.True(t, e.IsOverdue(now))
+	assert.Equal(t, "2025-07-09", e.FormattedDue())
+
+	e.Status = CANCELLED
+	assert.False(t, e.IsOverdue(now))
+
+	require.Error(t, e.SetDue("soon", now))
+	require.NoError(t, e.SetDue("", now))
+	assert.Nil(t, e.Due)
+}
+
+func TestEntries_PrintTasksTo(t *testing.T) {
+	now := time.Date(2025, time.July, 10, 12, 0, 0, 0, LocalTimezone)
+	due := HumanTime{time.Date(2025, time.July, 1, 0, 0, 0, 0, LocalTimezone)}
+
+	var buf bytes.Buffer
+
+	Entries{
+		{ID: 1, Summary: "Renew passport", Status: OPEN, Due: &due, Importance: HIGH, Source: &Source{Name: "manual"}},
+		{ID: 2, Summary: "Water plants", Status: DONE, Importance: LOW},
+	}.PrintTasksTo(&buf, now)
+
+	assert.Contains(t, buf.String(), "│ 1  │ overdue │ Renew passport │ 2025-07-01 │ high       │ manual │")
+	assert.Contains(t, buf.String(), "│ 2  │ done    │ Water plants   │            │ low        │        │")
+}
@@ -0,0 +1,212 @@
+package data
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aquasecurity/table"
+)
+
+// verboseMetadata are the metadata keys that are dropped first when entries
+// need to be compacted
+var verboseMetadata = []string{"Description", "Attendee", "Comment", "Organizer", "Class"}
+
+// TrimStep describes one step taken to reduce the size of the entries
+type TrimStep struct {
+	Action  string
+	Entries []string
+}
+
+type TrimReport struct {
+	Steps []TrimStep
+	Fits  bool
+}
+
+// FitFunc reports whether the entries fit the budget
+type FitFunc func(Entries) (bool, error)
+
+// Trim reduces the entries until they fit, in this order: drop entries with
+// low importance, drop verbose metadata, and collapse the days after tomorrow
+// into one-line digests, starting with the day furthest in the future
+func (es Entries) Trim(now time.Time, fits FitFunc) (Entries, TrimReport, error) {
+	var report TrimReport
+
+	ok, err := fits(es)
+	if err != nil || ok {
+		report.Fits = ok
+		return es, report, err
+	}
+
+	steps := []func(Entries) (Entries, []TrimStep){
+		dropLowImportance,
+		compactMetadata,
+	}
+
+	for _, step := range steps {
+		var s []TrimStep
+
+		es, s = step(es)
+		report.Steps = append(report.Steps, s...)
+
+		if ok, err = fits(es); err != nil || ok {
+			report.Fits = ok
+			return es, report, err
+		}
+	}
+
+	horizon := time.Date(now.Year(), now.Month(), now.Day()+2, 0, 0, 0, 0, LocalTimezone)
+
+	for _, day := range es.daysFrom(horizon) {
+		var s TrimStep
+
+		es, s = es.collapseDay(day)
+		report.Steps = append(report.Steps, s)
+
+		if ok, err = fits(es); err != nil || ok {
+			report.Fits = ok
+			return es, report, err
+		}
+	}
+
+	return es, report, nil
+}
+
+func dropLowImportance(es Entries) (Entries, []TrimStep) {
+	step := TrimStep{Action: "Dropped entries with low importance"}
+	result := make(Entries, 0, len(es))
+
+	for _, e := range es {
+		if e.Importance == LOW {
+			step.Entries = append(step.Entries, e.describe())
+			continue
+		}
+
+		result = append(result, e)
+	}
+
+	if len(step.Entries) == 0 {
+		return result, nil
+	}
+
+	return result, []TrimStep{step}
+}
+
+func compactMetadata(es Entries) (Entries, []TrimStep) {
+	step := TrimStep{Action: "Dropped verbose metadata (" + strings.Join(verboseMetadata, ", ") + ")"}
+	result := make(Entries, 0, len(es))
+
+	for _, e := range es {
+		var dropped bool
+
+		if e.Metadata != nil {
+			e.Metadata = maps.Clone(e.Metadata)
+
+			for _, k := range verboseMetadata {
+				if _, ok := e.Metadata[k]; ok {
+					delete(e.Metadata, k)
+					dropped = true
+				}
+			}
+		}
+
+		if dropped {
+			step.Entries = append(step.Entries, e.describe())
+		}
+
+		result = append(result, e)
+	}
+
+	if len(step.Entries) == 0 {
+		return result, nil
+	}
+
+	return result, []TrimStep{step}
+}
+
+// daysFrom returns the distinct days of all entries on or after from, the
+// last day first
+func (es Entries) daysFrom(from time.Time) []time.Time {
+	var days []time.Time
+
+	for _, e := range es {
+		d := e.day()
+		if d.Before(from) || slices.ContainsFunc(days, d.Equal) {
+			continue
+		}
+
+		days = append(days, d)
+	}
+
+	slices.SortFunc(days, func(a, b time.Time) int { return b.Compare(a) })
+
+	return days
+}
+
+// collapseDay replaces all entries on the day by a single digest entry
+func (es Entries) collapseDay(day time.Time) (Entries, TrimStep) {
+	step := TrimStep{Action: "Collapsed " + day.Format("2006-01-02") + " into a digest"}
+	digest := Entry{Date: HumanTime{day}, AllDay: true, Importance: LOW}
+	result := make(Entries, 0, len(es))
+
+	var titles []string
+
+	for _, e := range es {
+		if !e.day().Equal(day) {
+			result = append(result, e)
+			continue
+		}
+
+		step.Entries = append(step.Entries, e.describe())
+		titles = append(titles, e.Summary)
+
+		if e.Importance.rank() > digest.Importance.rank() {
+			digest.Importance = e.Importance
+		}
+	}
+
+	digest.Summary = "Digest: " + strings.Join(titles, "; ")
+
+	// Keep the entries sorted by date
+	i := slices.IndexFunc(result, func(e Entry) bool { return e.Date.After(day) })
+	if i < 0 {
+		i = len(result)
+	}
+
+	return slices.Insert(result, i, digest), step
+}
+
+func (e *Entry) day() time.Time {
+	return startOfDay(e.Date.Time)
+}
+
+func (e *Entry) describe() string {
+	return fmt.Sprintf("%s %s", e.FormattedDate(), e.Summary)
+}
+
+func (i Importance) rank() int {
+	switch i {
+	case HIGH:
+		return 3
+	case MEDIUM:
+		return 2
+	case LOW:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func (r TrimReport) PrintTo(w io.Writer) {
+	t := table.New(w)
+	t.AddHeaders("Action", "Entries")
+
+	for _, s := range r.Steps {
+		t.AddRow(s.Action, strings.Join(s.Entries, "\n"))
+	}
+
+	t.Render()
+}
@@ -0,0 +1,94 @@
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func trimTestEntries() Entries {
+	day := func(d int) HumanTime {
+		return HumanTime{time.Date(2025, 3, d, 10, 0, 0, 0, LocalTimezone)}
+	}
+
+	return Entries{
+		{Date: day(10), Summary: "Today", Importance: HIGH, Metadata: map[string]any{"Description": "long text", "Location": "Home"}},
+		{Date: day(11), Summary: "Tomorrow", Importance: LOW},
+		{Date: day(12), Summary: "Meeting", Importance: MEDIUM, Metadata: map[string]any{"Attendee": "A,B,C"}},
+		{Date: day(12), Summary: "Dinner", Importance: HIGH},
+		{Date: day(15), Summary: "Party", Importance: MEDIUM},
+	}
+}
+
+var trimTestNow = time.Date(2025, 3, 10, 8, 0, 0, 0, LocalTimezone)
+
+func TestEntries_Trim_Fits(t *testing.T) {
+	es := trimTestEntries()
+
+	result, report, err := es.Trim(trimTestNow, func(Entries) (bool, error) { return true, nil })
+
+	require.NoError(t, err)
+	assert.True(t, report.Fits)
+	assert.Empty(t, report.Steps, "Nothing should be trimmed when the entries fit")
+	assert.Equal(t, es, result)
+}
+
+func TestEntries_Trim_Order(t *testing.T) {
+	es := trimTestEntries()
+
+	var seen []Entries
+
+	// Never fits, so all steps are taken
+	result, report, err := es.Trim(trimTestNow, func(es Entries) (bool, error) {
+		seen = append(seen, es)
+		return false, nil
+	})
+
+	require.NoError(t, err)
+	assert.False(t, report.Fits)
+
+	actions := make([]string, 0, len(report.Steps))
+	for _, s := range report.Steps {
+		actions = append(actions, s.Action)
+	}
+
+	assert.Equal(t, []string{
+		"Dropped entries with low importance",
+		"Dropped verbose metadata (Description, Attendee, Comment, Organizer, Class)",
+		"Collapsed 2025-03-15 into a digest",
+		"Collapsed 2025-03-12 into a digest",
+	}, actions, "Steps should be taken in order, furthest day first")
+
+	require.Len(t, result, 3)
+	assert.Equal(t, "Today", result[0].Summary)
+	assert.Equal(t, map[string]any{"Location": "Home"}, result[0].Metadata, "Only verbose metadata should be dropped")
+	assert.Equal(t, "Digest: Meeting; Dinner", result[1].Summary)
+	assert.Equal(t, HIGH, result[1].Importance, "Digest should keep the highest importance")
+	assert.Equal(t, "Digest: Party", result[2].Summary)
+
+	assert.Equal(t, "long text", es[0].Metadata["Description"], "Original entries should not be modified")
+	assert.Len(t, seen, 5, "Fit should be checked before and after every step")
+}
+
+func TestEntries_Trim_StopsWhenFitting(t *testing.T) {
+	es := trimTestEntries()
+
+	result, report, err := es.Trim(trimTestNow, func(es Entries) (bool, error) {
+		return len(es) <= 4, nil
+	})
+
+	require.NoError(t, err)
+	assert.True(t, report.Fits)
+	require.Len(t, report.Steps, 1)
+	assert.Equal(t, []string{"2025-03-11 10:00 Tomorrow"}, report.Steps[0].Entries)
+	assert.Len(t, result, 4)
+}
+
+func TestEntry_describe(t *testing.T) {
+	midnight := HumanTime{time.Date(2025, 3, 11, 0, 0, 0, 0, LocalTimezone)}
+
+	assert.Equal(t, "2025-03-11 Holiday", (&Entry{Date: midnight, AllDay: true, Summary: "Holiday"}).describe())
+	assert.Equal(t, "2025-03-11 00:00 Night train", (&Entry{Date: midnight, Summary: "Night train"}).describe(), "An entry at midnight does not last all day")
+}
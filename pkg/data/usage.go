@@ -0,0 +1,84 @@
+package data
+
+import (
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/aquasecurity/table"
+)
+
+// UsageRecord is the number of tokens used by a single call to the LLM
+type UsageRecord struct {
+	ID               uint64    `gorm:"primaryKey"`
+	CreatedAt        time.Time `gorm:"index"`
+	Command          string    `gorm:"not null"`
+	Format           string
+	Persona          string
+	Provider         string `gorm:"not null"`
+	Model            string `gorm:"not null"`
+	PromptTokens     int    `gorm:"not null"`
+	CompletionTokens int    `gorm:"not null"`
+}
+
+// UsageTotal is the usage of a command and format with a model during a day
+// or month
+type UsageTotal struct {
+	Period           string
+	Command          string
+	Format           string
+	Provider         string
+	Model            string
+	Calls            int
+	PromptTokens     int
+	CompletionTokens int
+
+	// Cost is estimated from the price table; Priced is false if the model
+	// has no price
+	Cost   float64
+	Priced bool
+}
+
+type UsageReport []UsageTotal
+
+func (r UsageReport) PrintTo(w io.Writer) {
+	t := table.New(w)
+	t.AddHeaders("Period", "Command", "Format", "Model", "Calls", "Prompt tokens", "Completion tokens", "Cost")
+
+	var total UsageTotal
+
+	for _, u := range r {
+		t.AddRow(
+			u.Period,
+			u.Command,
+			u.Format,
+			u.Provider+"/"+u.Model,
+			strconv.Itoa(u.Calls),
+			strconv.Itoa(u.PromptTokens),
+			strconv.Itoa(u.CompletionTokens),
+			u.formatCost(),
+		)
+
+		total.Calls += u.Calls
+		total.PromptTokens += u.PromptTokens
+		total.CompletionTokens += u.CompletionTokens
+		total.Cost += u.Cost
+	}
+
+	t.AddFooters("Total", "", "", "",
+		strconv.Itoa(total.Calls),
+		strconv.Itoa(total.PromptTokens),
+		strconv.Itoa(total.CompletionTokens),
+		strconv.FormatFloat(total.Cost, 'f', 4, 64),
+	)
+
+	t.Render()
+}
+
+func (u UsageTotal) formatCost() string {
+	if !u.Priced {
+		return "-"
+	}
+
+	return strconv.FormatFloat(u.Cost, 'f', 4, 64)
+}
@@ -13,19 +13,19 @@ import (
 	"github.com/yaegashi/wtz.go"
 )
 
-func BuildEntriesFromRemote(remote string, daysBack, daysAhead uint, collection string) (data.Entries, error) {
+func BuildEntriesFromRemote(remote string, now time.Time, daysBack, daysAhead uint, collection string) (data.Entries, error) {
 	r, err := generic.GetBody(remote)
 	if err != nil {
 		return nil, err
 	}
 
-	return BuildEntriesFromICal(r, daysBack, daysAhead, collection)
+	return BuildEntriesFromICal(r, now, daysBack, daysAhead, collection)
 }
 
-func BuildEntriesFromICal(r []byte, daysBack, daysAhead uint, collection string) (data.Entries, error) {
+func BuildEntriesFromICal(r []byte, now time.Time, daysBack, daysAhead uint, collection string) (data.Entries, error) {
 	in := gocal.NewParser(bytes.NewReader(r))
-	start := time.Now().Add(-time.Duration(daysBack) * 24 * time.Hour)
-	end := time.Now().Add(time.Duration(daysAhead) * 24 * time.Hour)
+	start := now.Add(-time.Duration(daysBack) * 24 * time.Hour)
+	end := now.Add(time.Duration(daysAhead) * 24 * time.Hour)
 	in.Start, in.End = &start, &end
 
 	if err := in.Parse(); err != nil {
@@ -69,6 +69,7 @@ func newEventFromICal(event *gocal.Event, collection string) (*data.Entry, error
 		}
 
 		e.Date = data.HumanTime{Time: t}
+		e.AllDay = isICalDate(&event.RawStart)
 	}
 
 	e.Summary = event.Summary
@@ -79,13 +80,7 @@ func newEventFromICal(event *gocal.Event, collection string) (*data.Entry, error
 			return nil, err
 		}
 
-		d := data.HumanTime{Time: t}
-		e.SetMetadata("End", d.FormatDate())
-	}
-
-	if event.Start != nil && event.End != nil {
-		dur := event.End.Sub(*event.Start)
-		e.SetMetadata("Duration", dur.String())
+		e.End = &data.HumanTime{Time: t}
 	}
 
 	e.SetMetadataIfNotEmpty("Location", event.Location)
@@ -115,17 +110,21 @@ func collectAttendees(attendees []gocal.Attendee) string {
 }
 
 func parseICalRawDate(rs *gocal.RawDate, start *time.Time) (time.Time, error) {
-	if v, ok := rs.Params["VALUE"]; ok {
-		if v == "DATE" {
-			return parseICalDate(rs)
-		}
+	if isICalDate(rs) {
+		return parseICalDate(rs)
 	}
 
 	return parseICalTime(rs, start)
 }
 
+// isICalDate tells whether the value is a date without a time, which is how
+// iCalendar marks all-day events
+func isICalDate(rs *gocal.RawDate) bool {
+	return rs.Params["VALUE"] == "DATE"
+}
+
 func parseICalDate(rs *gocal.RawDate) (time.Time, error) {
-	return time.Parse("20060102", rs.Value)
+	return time.ParseInLocation("20060102", rs.Value, data.LocalTimezone)
 }
 
 func parseICalTime(rs *gocal.RawDate, start *time.Time) (time.Time, error) {
@@ -0,0 +1,58 @@
+package ical
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jovandeginste/spark-personal-assistant/pkg/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testCalendar = `BEGIN:VCALENDAR
+VERSION:2.0
+PRODID:-//Spark//Test//EN
+BEGIN:VEVENT
+UID:holiday@example.com
+DTSTAMP:20250601T000000Z
+DTSTART;VALUE=DATE:20250701
+DTEND;VALUE=DATE:20250708
+SUMMARY:Holiday
+TRANSP:TRANSPARENT
+END:VEVENT
+BEGIN:VEVENT
+UID:train@example.com
+DTSTAMP:20250601T000000Z
+DTSTART:20250703T000000Z
+DTEND:20250703T013000Z
+SUMMARY:Night train
+LOCATION:Station
+TRANSP:OPAQUE
+END:VEVENT
+END:VCALENDAR
+`
+
+func TestBuildEntriesFromICal(t *testing.T) {
+	now := time.Date(2025, time.July, 2, 12, 0, 0, 0, time.UTC)
+
+	entries, err := BuildEntriesFromICal([]byte(testCalendar), now, 7, 30, "family")
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	holiday, train := entries[0], entries[1]
+	if holiday.Summary != "Holiday" {
+		holiday, train = train, holiday
+	}
+
+	assert.True(t, holiday.AllDay, "Events with dates should be all day")
+	assert.True(t, time.Date(2025, time.July, 1, 0, 0, 0, 0, data.LocalTimezone).Equal(holiday.Date.Time))
+	require.NotNil(t, holiday.End)
+	assert.Equal(t, 7*24*time.Hour, holiday.Duration())
+	assert.Equal(t, "family", holiday.Metadata["Collection"])
+	assert.NotContains(t, holiday.Metadata, "End", "The end should not be stored as metadata")
+	assert.NotContains(t, holiday.Metadata, "Duration")
+
+	assert.False(t, train.AllDay, "Events at midnight should not be all day")
+	assert.Equal(t, 90*time.Minute, train.Duration())
+	assert.Equal(t, "Station", train.Metadata["Location"])
+}
@@ -12,7 +12,7 @@ import (
 	"github.com/jovandeginste/spark-personal-assistant/pkg/data"
 )
 
-func BuildEntriesFromFile(file string) (data.Entries, error) {
+func BuildEntriesFromFile(file string, now time.Time) (data.Entries, error) {
 	f, err := os.Open(file)
 	if err != nil {
 		return nil, err
@@ -35,7 +35,7 @@ func BuildEntriesFromFile(file string) (data.Entries, error) {
 			continue
 		}
 
-		bday, age, err := parseBday(card.PreferredValue(vcard.FieldBirthday))
+		bday, age, err := parseBday(card.PreferredValue(vcard.FieldBirthday), now)
 		if err != nil {
 			continue
 		}
@@ -54,13 +54,14 @@ func BuildEntriesFromFile(file string) (data.Entries, error) {
 	return entries, nil
 }
 
-func parseBday(bday string) (time.Time, int, error) {
+// parseBday returns the birthday in the year of now, and the age on that day
+func parseBday(bday string, now time.Time) (time.Time, int, error) {
 	if bday == "" {
 		return time.Time{}, 0, errors.New("no birthday")
 	}
 
 	if strings.HasPrefix(bday, "--") {
-		bday = fmt.Sprintf("%d%s", time.Now().Year(), strings.TrimPrefix(bday, "--"))
+		bday = fmt.Sprintf("%d%s", now.Year(), strings.TrimPrefix(bday, "--"))
 	}
 
 	bdayDate, err := time.Parse("20060102", bday)
@@ -68,7 +69,7 @@ func parseBday(bday string) (time.Time, int, error) {
 		return time.Time{}, 0, err
 	}
 
-	age := time.Now().Year() - bdayDate.Year()
+	age := now.Year() - bdayDate.Year()
 	bdayDate = bdayDate.AddDate(age, 0, 0)
 
 	return bdayDate, age, nil
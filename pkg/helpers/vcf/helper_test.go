@@ -8,7 +8,6 @@ import (
 	"testing"
 	"time"
 
-	"github.com/awterman/monkey"
 	_ "github.com/emersion/go-vcard" // Import needed for test data creation implicitly
 	"github.com/jovandeginste/spark-personal-assistant/pkg/data"
 	"github.com/stretchr/testify/assert"
@@ -28,10 +27,8 @@ func createTempVCFFile(t *testing.T, content string) string {
 
 // TestBuildEntriesFromFile tests the BuildEntriesFromFile function using temporary files.
 func TestBuildEntriesFromFile(t *testing.T) {
-	// Mock time.Now() for deterministic parseBday calls within BuildEntriesFromFile
+	// Fix the time for deterministic parseBday calls within BuildEntriesFromFile
 	fakeNow := time.Date(2024, time.March, 15, 12, 0, 0, 0, time.UTC)
-	patchTime := monkey.Func(nil, time.Now, func() time.Time { return fakeNow })
-	defer patchTime.Reset()
 
 	type expectedEntry struct {
 		Date     time.Time
@@ -209,7 +206,7 @@ INVALIDEND:VCARD`, // Malformed property might cause go-vcard.NewDecoder(f).Deco
 			var err error
 
 			// Call the function under test
-			entries, err = BuildEntriesFromFile(filePath)
+			entries, err = BuildEntriesFromFile(filePath, fakeNow)
 
 			if tt.expectError {
 				assert.Error(t, err, "Expected an error")
@@ -110,6 +110,8 @@ func newEventFromOpenMeteo(wd *WeatherData, location string, day int) (*data.Ent
 
 	e := &data.Entry{
 		Date:    data.HumanTime{Time: parsedDate},
+		End:     &data.HumanTime{Time: parsedDate.AddDate(0, 0, 1)},
+		AllDay:  true,
 		Summary: fmt.Sprintf("Weather for %s in %s", parsedDate.Format("Monday"), location),
 	}
 
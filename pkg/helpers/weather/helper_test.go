@@ -479,6 +479,9 @@ func TestNewEventFromOpenMeteo(t *testing.T) {
 
 				// Compare relevant fields
 				assert.True(t, tt.expectedEntry.Date.Time.Equal(entry.Date.Time), "Date mismatch")
+				assert.True(t, entry.AllDay, "Forecasts should be all day")
+				require.NotNil(t, entry.End)
+				assert.True(t, entry.Date.AddDate(0, 0, 1).Equal(entry.End.Time), "Forecasts should end at the next day")
 				assert.Equal(t, tt.expectedEntry.Summary, entry.Summary, "Summary mismatch")
 
 				// Metadata comparison requires deep equal